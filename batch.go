@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"profile-extractor/internal/api"
+	"profile-extractor/internal/interview"
+	"profile-extractor/pkg/extractor"
+)
+
+const (
+	batchOK      = "ok"
+	batchFailed  = "failed"
+	batchSkipped = "skipped"
+)
+
+// batchResult — итог обработки одного файла; строка JSONL отчета
+type batchResult struct {
+	Path        string `json:"path"`
+	InterviewID string `json:"interview_id,omitempty"`
+	Status      string `json:"status"`
+	Output      string `json:"output,omitempty"`
+	// Resumed — этап 1 взят из журнала прерванного запуска
+	Resumed    bool             `json:"resumed,omitempty"`
+	DurationMS int64            `json:"duration_ms"`
+	Usage      *api.UsageReport `json:"usage,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// runBatch обрабатывает несколько интервью: profile-extractor batch [флаги] файлы, каталоги или шаблоны.
+// Интервью с уже сохраненным профилем пропускаются, прерванные продолжаются
+// по журналу. Возвращает ошибку, если хотя бы одно интервью не обработано.
+func runBatch(ctx context.Context, args []string) error {
+	flags := newFlagSet("batch", "[флаги] [файлы, каталоги или шаблоны]")
+	opts := commonFlags(flags)
+	opts.modelFlags(flags)
+	workers := flags.Int("workers", 2, "сколько интервью обрабатывается одновременно")
+	outputDir := flags.String("out", "", "каталог для профилей (по умолчанию output_dir из конфигурации)")
+	force := flags.Bool("force", false, "обработать заново интервью с сохраненным профилем")
+	reportPath := flags.String("report", "", "JSONL отчет (по умолчанию <out>/batch_<время>.jsonl)")
+	journalPath := flags.String("journal", "", "журнал для продолжения прерванной обработки (по умолчанию <out>/batch_journal.jsonl)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	// Вывод нескольких интервью перемешивается, поэтому промпты,
+	// промежуточные профили и прогресс потока не показываются даже с -v
+	e, err := opts.extractor(false)
+	if err != nil {
+		return err
+	}
+	if *outputDir == "" {
+		*outputDir = e.Config().OutputDir
+	}
+
+	inputs := flags.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"input"}
+	}
+	paths, err := expandInputs(inputs)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no interview files in %v", inputs)
+	}
+
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		return fmt.Errorf("error creating output dir: %w", err)
+	}
+	if *reportPath == "" {
+		*reportPath = filepath.Join(*outputDir, fmt.Sprintf("batch_%s.jsonl", time.Now().Format("20060102_150405")))
+	}
+	report, err := newBatchReport(*reportPath)
+	if err != nil {
+		return err
+	}
+	defer report.Close()
+
+	if *journalPath == "" {
+		*journalPath = filepath.Join(*outputDir, "batch_journal.jsonl")
+	}
+	journal, err := openJournal(*journalPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := journal.Close(); err != nil {
+			log.Printf("Job journal close failed: %v", err)
+		}
+	}()
+	for _, path := range paths {
+		if journal.Last(journalKey(path)) == nil {
+			if err := journal.Record(journalEntry{Path: journalKey(path), State: stagePending}); err != nil {
+				return fmt.Errorf("error writing job journal: %w", err)
+			}
+		}
+	}
+
+	if *workers < 1 {
+		*workers = 1
+	}
+	log.Printf("Batch: %d interviews, %d workers, output: %s", len(paths), *workers, *outputDir)
+
+	results := make([]*batchResult, len(paths))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	var done int
+	var doneMu sync.Mutex
+
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result := processFile(ctx, e, paths[i], *outputDir, *force, journal)
+				results[i] = result
+				if err := report.Write(result); err != nil {
+					log.Printf("Batch report write failed: %v", err)
+				}
+
+				doneMu.Lock()
+				done++
+				log.Printf("[%d/%d] %s: %s", done, len(paths), paths[i], result.Status)
+				doneMu.Unlock()
+			}
+		}()
+	}
+
+	for i := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if !opts.quiet {
+		printBatchSummary(results)
+		fmt.Printf("\nОтчет: %s\n", *reportPath)
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if failed := countFailed(results); failed > 0 {
+		return &cliError{code: exitPartial, err: fmt.Errorf("%d of %d interviews failed", failed, len(paths))}
+	}
+	return nil
+}
+
+// processFile обрабатывает один файл интервью; ошибка попадает в результат.
+// Смены этапов записываются в журнал, сохраненный результат извлечения
+// из журнала используется, если файл с тех пор не менялся.
+func processFile(ctx context.Context, e *extractor.Extractor, path, outputDir string, force bool, journal *jobJournal) *batchResult {
+	start := time.Now()
+	result := &batchResult{Path: path}
+	entry := journalEntry{Path: journalKey(path)}
+	record := func(state string, extraction *extractor.Extraction) {
+		entry.State = state
+		entry.Extraction = extraction
+		if err := journal.Record(entry); err != nil {
+			log.Printf("Job journal write failed: %v", err)
+		}
+	}
+	finish := func(status string, err error) *batchResult {
+		result.Status = status
+		result.DurationMS = time.Since(start).Milliseconds()
+		if err != nil {
+			result.Error = err.Error()
+			entry.Error = result.Error
+			record(stageFailed, nil)
+		}
+		return result
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return finish(batchFailed, fmt.Errorf("error reading interview file: %w", err))
+	}
+	entry.Checksum = fileChecksum(data)
+	interviewObj, err := interview.ParseInterviewJSON(data)
+	if err != nil {
+		return finish(batchFailed, err)
+	}
+	result.InterviewID = interviewObj.InterviewID
+	entry.InterviewID = interviewObj.InterviewID
+
+	output := outputPath(outputDir, interviewObj.InterviewID)
+	if _, err := os.Stat(output); err == nil && !force {
+		result.Output = output
+		return finish(batchSkipped, nil)
+	}
+
+	extractOpts := []extractor.ExtractOption{
+		extractor.OnStage(func(stage extractor.Stage, extraction *extractor.Extraction) {
+			record(string(stage), extraction)
+		}),
+	}
+	if !force {
+		if extraction := journal.Extraction(entry.Path, entry.Checksum); extraction != nil {
+			extractOpts = append(extractOpts, extractor.ResumeFrom(extraction))
+			result.Resumed = true
+		}
+	}
+
+	profile, report, err := e.Extract(ctx, interviewObj, extractOpts...)
+	result.Usage = report.Usage
+	var profileJSON []byte
+	if err == nil {
+		profileJSON, err = profile.JSON()
+	}
+	if err == nil {
+		err = saveProfile(output, profileJSON)
+	}
+	if err != nil {
+		return finish(batchFailed, err)
+	}
+
+	result.Output = output
+	entry.Output = output
+	record(stageDone, nil)
+	return finish(batchOK, nil)
+}
+
+// expandInputs превращает аргументы в список файлов: каталог дает свои *.json,
+// шаблон раскрывается через filepath.Glob. Повторы убираются.
+func expandInputs(inputs []string) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(matches ...string) {
+		for _, path := range matches {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	for _, input := range inputs {
+		info, err := os.Stat(input)
+		switch {
+		case err == nil && info.IsDir():
+			matches, _ := filepath.Glob(filepath.Join(input, "*.json"))
+			add(matches...)
+		case err == nil:
+			add(input)
+		default:
+			matches, err := filepath.Glob(input)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", input, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no interview files match %q", input)
+			}
+			add(matches...)
+		}
+	}
+
+	return paths, nil
+}
+
+// batchReport пишет результаты в JSONL по мере завершения интервью,
+// чтобы прерванный запуск оставил отчет о сделанном
+type batchReport struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func newBatchReport(path string) (*batchReport, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating batch report: %w", err)
+	}
+	return &batchReport{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+func (r *batchReport) Write(result *batchResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(result)
+}
+
+func (r *batchReport) Close() error {
+	return r.file.Close()
+}
+
+// printBatchSummary выводит таблицу результатов и итоги
+func printBatchSummary(results []*batchResult) {
+	var ok, failed, skipped, tokens int
+	var cost float64
+	var duration time.Duration
+
+	fmt.Println("\nРезультаты пакетной обработки:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  ФАЙЛ\tИНТЕРВЬЮ\tСТАТУС\tТОКЕНЫ\tСТОИМОСТЬ\tВРЕМЯ\tОШИБКА")
+	for _, result := range results {
+		if result == nil {
+			// Не начато из-за прерывания
+			continue
+		}
+
+		switch result.Status {
+		case batchOK:
+			ok++
+		case batchFailed:
+			failed++
+		case batchSkipped:
+			skipped++
+		}
+
+		var total api.StageUsage
+		if result.Usage != nil {
+			total = result.Usage.Total
+		}
+		tokens += total.TotalTokens
+		cost += total.CostUSD
+		elapsed := time.Duration(result.DurationMS) * time.Millisecond
+		duration += elapsed
+
+		status := result.Status
+		if result.Resumed {
+			status += " (resumed)"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%d\t~$%.4f\t%v\t%s\n", filepath.Base(result.Path), result.InterviewID,
+			status, total.TotalTokens, total.CostUSD, elapsed.Round(time.Millisecond), result.Error)
+	}
+	w.Flush()
+
+	fmt.Printf("\nУспешно: %d, с ошибкой: %d, пропущено: %d, токенов: %d, ~$%.4f, суммарное время: %v\n",
+		ok, failed, skipped, tokens, cost, duration.Round(time.Millisecond))
+}
+
+// countFailed возвращает число интервью, которые не удалось обработать
+func countFailed(results []*batchResult) int {
+	failed := 0
+	for _, result := range results {
+		if result != nil && result.Status == batchFailed {
+			failed++
+		}
+	}
+	return failed
+}
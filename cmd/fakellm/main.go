@@ -0,0 +1,55 @@
+// Команда fakellm запускает OpenAI-совместимый сервер, который отвечает
+// профилями по dictionary.yaml и умеет имитировать сбои провайдера.
+//
+//	go run ./cmd/fakellm -addr :8090 -rate-limit 0.2 -truncate 0.3
+//	LLM_PROVIDER=local LLM_BASE_URL=http://localhost:8090/v1 go run . extract input/interview.json
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"profile-extractor/internal/fakellm"
+	"profile-extractor/internal/schema"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8090", "адрес сервера")
+	dictionary := flag.String("dictionary", "config/dictionary.yaml", "словарь полей профиля")
+	values := flag.String("values", fakellm.ValuesRules, "значения полей: rules или random")
+	seed := flag.Int64("seed", 1, "seed для случайных значений и сбоев")
+
+	var faults fakellm.Faults
+	flag.DurationVar(&faults.Latency, "latency", 0, "задержка перед каждым ответом")
+	flag.DurationVar(&faults.LatencyJitter, "latency-jitter", 0, "случайная добавка к задержке")
+	flag.Float64Var(&faults.RateLimit, "rate-limit", 0, "доля ответов 429")
+	flag.DurationVar(&faults.RetryAfter, "retry-after", 0, "заголовок Retry-After для ответов 429")
+	flag.Float64Var(&faults.ServerError, "server-error", 0, "доля ответов 500")
+	flag.Float64Var(&faults.Malformed, "malformed", 0, "доля ответов с испорченным JSON")
+	flag.Float64Var(&faults.Truncate, "truncate", 0, "доля ответов, оборванных по max_tokens")
+	flag.Parse()
+
+	if *values != fakellm.ValuesRules && *values != fakellm.ValuesRandom {
+		log.Fatalf("unknown -values %q: expected %s or %s", *values, fakellm.ValuesRules, fakellm.ValuesRandom)
+	}
+
+	yamlContent, err := os.ReadFile(*dictionary)
+	if err != nil {
+		log.Fatal("Error reading dictionary:", err)
+	}
+	schemaFields, err := schema.ParseYAMLSchema(yamlContent)
+	if err != nil {
+		log.Fatal("Error parsing schema:", err)
+	}
+
+	server := fakellm.New(schemaFields, fakellm.Options{
+		Values: *values,
+		Seed:   *seed,
+		Faults: faults,
+	})
+
+	log.Printf("Fake LLM listening on http://%s (%d fields, values: %s)", *addr, len(schemaFields), *values)
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
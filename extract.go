@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"profile-extractor/internal/interview"
+	"profile-extractor/internal/schema"
+	"profile-extractor/internal/validator"
+)
+
+// runExtract извлекает профиль из одного интервью
+func runExtract(ctx context.Context, args []string) error {
+	flags := newFlagSet("extract", "[флаги] <interview.json>")
+	opts := commonFlags(flags)
+	opts.modelFlags(flags)
+	output := flags.String("o", "", `файл профиля; "-" — вывод в stdout (по умолчанию <out>/profile_<id>.json)`)
+	outputDir := flags.String("out", "", "каталог для профиля (по умолчанию output_dir из конфигурации)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return usageError("extract expects one interview file")
+	}
+
+	e, err := opts.extractor(true)
+	if err != nil {
+		return err
+	}
+
+	interviewObj, err := loadInterview(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	profile, report, err := e.Extract(ctx, interviewObj)
+	if err != nil {
+		return fmt.Errorf("error processing interview: %w", err)
+	}
+	profileJSON, err := profile.JSON()
+	if err != nil {
+		return err
+	}
+	usage := report.Usage
+
+	// В stdout идет только профиль, чтобы его можно было передать дальше по конвейеру
+	if *output == "-" {
+		fmt.Println(string(profileJSON))
+		if !opts.quiet {
+			printUsageSummary(os.Stderr, usage)
+		}
+		return nil
+	}
+
+	path := *output
+	if path == "" {
+		dir := *outputDir
+		if dir == "" {
+			dir = e.Config().OutputDir
+		}
+		path = outputPath(dir, interviewObj.InterviewID)
+	}
+	if err := saveProfile(path, profileJSON); err != nil {
+		return err
+	}
+
+	if opts.quiet {
+		return nil
+	}
+
+	fmt.Printf("\n✅ Профиль успешно создан из интервью и сохранен в %s!\n", path)
+	if opts.verbose {
+		fmt.Println("\nМетаданные интервью:")
+		metadataJSON, err := json.MarshalIndent(profile.Metadata["source_interview"], "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling interview metadata: %w", err)
+		}
+		fmt.Println(string(metadataJSON))
+
+		fmt.Println("\nРезультат:")
+		fmt.Println(string(profileJSON))
+	}
+
+	printUsageSummary(os.Stdout, usage)
+	return nil
+}
+
+// loadInterview читает и разбирает файл интервью
+func loadInterview(path string) (*interview.Interview, error) {
+	interviewData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading interview file: %w", err)
+	}
+	interviewObj, err := interview.ParseInterviewJSON(interviewData)
+	if err != nil {
+		return nil, err
+	}
+	return interviewObj, nil
+}
+
+// outputPath возвращает путь профиля интервью в каталоге результатов
+func outputPath(outputDir, interviewID string) string {
+	return filepath.Join(outputDir, fmt.Sprintf("profile_%s.json", interviewID))
+}
+
+// saveProfile записывает профиль в файл, создавая каталог
+func saveProfile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating output dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error saving profile: %w", err)
+	}
+	return nil
+}
+
+// runValidate проверяет сохраненный профиль по словарю без обращения к модели
+func runValidate(ctx context.Context, args []string) error {
+	flags := newFlagSet("validate", "[флаги] <profile.json>")
+	opts := commonFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return usageError("validate expects one profile file")
+	}
+	if err := opts.setup(); err != nil {
+		return err
+	}
+
+	schemaFields, err := opts.loadSchema()
+	if err != nil {
+		return err
+	}
+
+	path := flags.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading profile: %w", err)
+	}
+
+	err = validator.ValidateProfileJSON(string(data), schemaFields)
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fmt.Printf("❌ Профиль %s не соответствует схеме, ошибок: %d\n", path, len(validationErrs))
+		for _, message := range validationErrs.Messages() {
+			fmt.Println("  -", message)
+		}
+		return &cliError{code: exitInvalid}
+	}
+	if err != nil {
+		return &cliError{code: exitInvalid, err: err}
+	}
+
+	if !opts.quiet {
+		fmt.Printf("✅ Профиль %s соответствует схеме\n", path)
+	}
+	return nil
+}
+
+// runSchema выводит словарь в виде JSON Schema (как в response_format),
+// списком полей или Go структурами для сервисов, читающих профили
+func runSchema(ctx context.Context, args []string) error {
+	flags := newFlagSet("schema", "[флаги] [jsonschema|fields|codegen]")
+	opts := commonFlags(flags)
+	output := flags.String("o", "", "файл результата (по умолчанию stdout)")
+	packageName := flags.String("package", "profile", "имя пакета для codegen")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return usageError("schema expects at most one action")
+	}
+	if err := opts.setup(); err != nil {
+		return err
+	}
+
+	action := "jsonschema"
+	if flags.NArg() == 1 {
+		action = flags.Arg(0)
+	}
+
+	schemaFields, err := opts.loadSchema()
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	switch action {
+	case "jsonschema":
+		data, err := json.MarshalIndent(schema.ToJSONSchema(schemaFields), "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling JSON Schema: %w", err)
+		}
+		out.Write(data)
+		out.WriteByte('\n')
+	case "fields":
+		keys := make([]string, 0, len(schemaFields))
+		for key := range schemaFields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintln(&out, schemaFields[key])
+		}
+	case "codegen":
+		if !token.IsIdentifier(*packageName) {
+			return usageError("invalid package name %q", *packageName)
+		}
+		code, err := schema.GenerateGo(schemaFields, *packageName, filepath.ToSlash(opts.schemaPath))
+		if err != nil {
+			return fmt.Errorf("error generating Go code: %w", err)
+		}
+		out.Write(code)
+	default:
+		return usageError("unknown schema action %q: expected jsonschema, fields or codegen", action)
+	}
+
+	if *output == "" {
+		_, err := os.Stdout.Write(out.Bytes())
+		return err
+	}
+	if err := os.WriteFile(*output, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", *output, err)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultAnthropicBaseURL = "https://api.anthropic.com"
+	defaultAnthropicModel   = "claude-3-5-haiku-latest"
+	anthropicVersion        = "2023-06-01"
+)
+
+// AnthropicClient работает с Anthropic Messages API
+type AnthropicClient struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+type AnthropicRequest struct {
+	Model       string    `json:"model"`
+	System      string    `json:"system,omitempty"`
+	Messages    []Message `json:"messages"`
+	Temperature float64   `json:"temperature"`
+	MaxTokens   int       `json:"max_tokens"`
+	Stream      bool      `json:"stream,omitempty"`
+}
+
+type AnthropicResponse struct {
+	Model      string             `json:"model"`
+	Content    []AnthropicContent `json:"content"`
+	StopReason string             `json:"stop_reason"`
+	Usage      AnthropicUsage     `json:"usage"`
+	Error      *APIError          `json:"error,omitempty"`
+}
+
+type AnthropicContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type AnthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// AnthropicStreamEvent — одно событие потокового ответа Messages API
+type AnthropicStreamEvent struct {
+	Type    string             `json:"type"`
+	Message *AnthropicResponse `json:"message,omitempty"`
+	Delta   struct {
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *AnthropicUsage `json:"usage,omitempty"`
+	Error *APIError       `json:"error,omitempty"`
+}
+
+func NewAnthropicClient(cfg ProviderConfig) *AnthropicClient {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL(ProviderAnthropic)
+	}
+	model := cfg.Model
+	if model == "" {
+		model = DefaultModel(ProviderAnthropic)
+	}
+
+	return &AnthropicClient{
+		apiKey:  cfg.APIKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		client:  newHTTPClient(cfg),
+	}
+}
+
+func (c *AnthropicClient) Name() string {
+	return ProviderAnthropic
+}
+
+func (c *AnthropicClient) Capabilities() Capabilities {
+	return Capabilities{
+		SystemPrompt: true,
+		Streaming:    true,
+	}
+}
+
+func (c *AnthropicClient) Complete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = c.model
+	}
+
+	// Messages API принимает системный промпт отдельным полем
+	var system []string
+	var messages []Message
+	for _, msg := range req.Messages {
+		if msg.Role == "system" {
+			system = append(system, msg.Content)
+			continue
+		}
+		messages = append(messages, msg)
+	}
+
+	reqBody := AnthropicRequest{
+		Model:       model,
+		System:      strings.Join(system, "\n\n"),
+		Messages:    messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+	}
+
+	headers := map[string]string{
+		"x-api-key":         c.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+
+	if req.OnProgress != nil {
+		return c.stream(ctx, headers, reqBody, req.OnProgress)
+	}
+
+	body, err := postJSON(ctx, c.client, c.baseURL+"/v1/messages", headers, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var anthropicResp AnthropicResponse
+	if err := json.Unmarshal(body, &anthropicResp); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+
+	if anthropicResp.Error != nil {
+		return nil, classifyErrorMessage(anthropicResp.Error.Message)
+	}
+
+	if anthropicResp.StopReason == "refusal" {
+		return nil, &Error{Kind: ErrContentFiltered, Message: "response refused by model"}
+	}
+
+	var content strings.Builder
+	for _, block := range anthropicResp.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("no text content returned from API")
+	}
+
+	if anthropicResp.Model != "" {
+		model = anthropicResp.Model
+	}
+
+	return &ChatResponse{
+		Content:      content.String(),
+		Model:        model,
+		FinishReason: anthropicFinishReason(anthropicResp.StopReason),
+		Usage: Usage{
+			PromptTokens:     anthropicResp.Usage.InputTokens,
+			CompletionTokens: anthropicResp.Usage.OutputTokens,
+			TotalTokens:      anthropicResp.Usage.InputTokens + anthropicResp.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// stream выполняет запрос с stream: true и собирает ответ из событий
+func (c *AnthropicClient) stream(ctx context.Context, headers map[string]string, reqBody AnthropicRequest, progress ProgressFunc) (*ChatResponse, error) {
+	reqBody.Stream = true
+
+	result := &ChatResponse{Model: reqBody.Model}
+	acc := newStreamAccumulator(progress)
+	var stopReason string
+
+	err := postStream(ctx, c.client, c.baseURL+"/v1/messages", headers, reqBody, func(data []byte) error {
+		var event AnthropicStreamEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("error unmarshaling stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "error":
+			if event.Error != nil {
+				return classifyErrorMessage(event.Error.Message)
+			}
+			return fmt.Errorf("stream error: %s", data)
+		case "message_start":
+			if event.Message != nil {
+				if event.Message.Model != "" {
+					result.Model = event.Message.Model
+				}
+				result.Usage.PromptTokens = event.Message.Usage.InputTokens
+			}
+		case "content_block_delta":
+			acc.write(event.Delta.Text)
+		case "message_delta":
+			if event.Delta.StopReason != "" {
+				stopReason = event.Delta.StopReason
+			}
+			if event.Usage != nil {
+				result.Usage.CompletionTokens = event.Usage.OutputTokens
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if stopReason == "refusal" {
+		return nil, &Error{Kind: ErrContentFiltered, Message: "response refused by model"}
+	}
+	if acc.content.Len() == 0 {
+		return nil, fmt.Errorf("no text content returned from API")
+	}
+
+	acc.done(result.Usage.CompletionTokens)
+	result.Content = acc.content.String()
+	result.FinishReason = anthropicFinishReason(stopReason)
+	result.Usage.TotalTokens = result.Usage.PromptTokens + result.Usage.CompletionTokens
+	return result, nil
+}
+
+// anthropicFinishReason приводит stop_reason к значениям finish_reason в стиле OpenAI
+func anthropicFinishReason(stopReason string) string {
+	switch stopReason {
+	case "max_tokens":
+		return "length"
+	case "end_turn", "stop_sequence":
+		return "stop"
+	default:
+		return stopReason
+	}
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// CacheConfig задает дисковый кэш ответов модели
+type CacheConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir"`
+	// TTL — время жизни записи; 0 — без ограничения
+	TTL time.Duration `yaml:"ttl"`
+	// MaxSizeMB ограничивает размер каталога; 0 — без ограничения
+	MaxSizeMB int64 `yaml:"max_size_mb"`
+}
+
+// DefaultCacheConfig возвращает настройки кэша по умолчанию
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		Enabled:   true,
+		Dir:       ".cache",
+		TTL:       7 * 24 * time.Hour,
+		MaxSizeMB: 100,
+	}
+}
+
+// ResponseCache хранит ответы модели на диске по хэшу запроса
+type ResponseCache struct {
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+}
+
+// NewResponseCache создает кэш в каталоге cfg.Dir
+func NewResponseCache(cfg CacheConfig) (*ResponseCache, error) {
+	dir := cfg.Dir
+	if dir == "" {
+		dir = DefaultCacheConfig().Dir
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating cache dir: %w", err)
+	}
+
+	return &ResponseCache{
+		dir:      dir,
+		ttl:      cfg.TTL,
+		maxBytes: cfg.MaxSizeMB * 1024 * 1024,
+	}, nil
+}
+
+type cacheEntry struct {
+	CreatedAt time.Time    `json:"created_at"`
+	Response  ChatResponse `json:"response"`
+}
+
+// cacheKey описывает все, что влияет на ответ модели
+type cacheKey struct {
+	Provider       string          `json:"provider"`
+	Endpoint       string          `json:"endpoint"`
+	Model          string          `json:"model"`
+	Temperature    float64         `json:"temperature"`
+	MaxTokens      int             `json:"max_tokens"`
+	Messages       []Message       `json:"messages"`
+	ResponseFormat *ResponseFormat `json:"response_format"`
+}
+
+// Key возвращает sha256 запроса к провайдеру на конкретном endpoint
+func (c *ResponseCache) Key(provider, endpoint string, req ChatRequest) string {
+	data, _ := json.Marshal(cacheKey{
+		Provider:       provider,
+		Endpoint:       endpoint,
+		Model:          req.Model,
+		Temperature:    req.Temperature,
+		MaxTokens:      req.MaxTokens,
+		Messages:       req.Messages,
+		ResponseFormat: req.ResponseFormat,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *ResponseCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Get возвращает сохраненный ответ. Просроченные записи удаляются.
+func (c *ResponseCache) Get(key string) (*ChatResponse, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		os.Remove(path)
+		return nil, false
+	}
+	if c.ttl > 0 && time.Since(entry.CreatedAt) > c.ttl {
+		os.Remove(path)
+		return nil, false
+	}
+
+	// Время изменения используется для вытеснения давно не читанных записей
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	return &entry.Response, true
+}
+
+// Put сохраняет ответ и вытесняет старые записи при превышении размера
+func (c *ResponseCache) Put(key string, resp *ChatResponse) error {
+	data, err := json.Marshal(cacheEntry{CreatedAt: time.Now(), Response: *resp})
+	if err != nil {
+		return fmt.Errorf("error marshaling cache entry: %w", err)
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating cache dir: %w", err)
+	}
+	// Запись через временный файл, чтобы прерванный запуск не оставил битую
+	// запись, а параллельные воркеры не писали в один файл
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error writing cache entry: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error writing cache entry: %w", err)
+	}
+
+	return c.prune()
+}
+
+// prune удаляет самые давно использованные записи, пока кэш больше лимита
+func (c *ResponseCache) prune() error {
+	if c.maxBytes <= 0 {
+		return nil
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var total int64
+
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files = append(files, file{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error scanning cache: %w", err)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, f := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+		}
+	}
+
+	return nil
+}
+
+// CachingProvider отдает сохраненные ответы вместо повторных запросов к модели
+type CachingProvider struct {
+	Provider
+	cache    *ResponseCache
+	endpoint string
+}
+
+// WithCache оборачивает провайдера кэшем ответов. endpoint входит в ключ,
+// чтобы одинаковые имена моделей на разных серверах не смешивались.
+func WithCache(p Provider, cache *ResponseCache, endpoint string) Provider {
+	if cache == nil {
+		return p
+	}
+	return &CachingProvider{Provider: p, cache: cache, endpoint: endpoint}
+}
+
+func (c *CachingProvider) Complete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	key := c.cache.Key(c.Provider.Name(), c.endpoint, req)
+
+	if cached, ok := c.cache.Get(key); ok {
+		// Повторно за ответ не платим, поэтому токены не учитываются
+		cached.Usage = Usage{}
+		cached.Cached = true
+		return cached, nil
+	}
+
+	resp, err := c.Provider.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ошибка записи не должна ронять запуск, за который уже заплатили
+	if err := c.cache.Put(key, resp); err != nil {
+		loggerFromContext(ctx).Printf("Cache write failed: %v", err)
+	}
+
+	return resp, nil
+}
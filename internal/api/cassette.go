@@ -0,0 +1,217 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// ErrCassetteMiss — в кассете нет ответа на запрос. Такой запрос не повторяется.
+var ErrCassetteMiss = errors.New("no recorded interaction for request")
+
+// CassetteConfig включает запись или воспроизведение HTTP обмена с провайдером
+type CassetteConfig struct {
+	// Mode — record, replay или пусто (обычная работа с сетью)
+	Mode string `yaml:"mode"`
+	Path string `yaml:"path"`
+}
+
+// Interaction — один записанный запрос и ответ
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest хранит запрос без заголовков, чтобы ключи не попали в кассету
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// BodySHA256 — ключ поиска при воспроизведении
+	BodySHA256 string `json:"body_sha256"`
+	Body       string `json:"body"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Cassette — http.RoundTripper, который записывает обмен с провайдером в файл
+// или отдает записанные ответы без обращения к сети
+type Cassette struct {
+	mode      string
+	path      string
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	// played — сколько ответов на каждый ключ уже отдано при воспроизведении
+	played map[string]int
+}
+
+// NewCassette открывает кассету. В режиме replay файл должен существовать,
+// в режиме record новые записи добавляются к уже имеющимся.
+func NewCassette(cfg CassetteConfig) (*Cassette, error) {
+	if cfg.Mode != CassetteRecord && cfg.Mode != CassetteReplay {
+		return nil, fmt.Errorf("unknown cassette mode: %q", cfg.Mode)
+	}
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("cassette path is required")
+	}
+
+	c := &Cassette{
+		mode:      cfg.Mode,
+		path:      cfg.Path,
+		transport: http.DefaultTransport,
+		played:    make(map[string]int),
+	}
+
+	data, err := os.ReadFile(cfg.Path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &c.interactions); err != nil {
+			return nil, fmt.Errorf("error parsing cassette %s: %w", cfg.Path, err)
+		}
+	case os.IsNotExist(err) && cfg.Mode == CassetteRecord:
+	default:
+		return nil, fmt.Errorf("error reading cassette: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	sum := sha256.Sum256(body)
+	recorded := RecordedRequest{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		BodySHA256: hex.EncodeToString(sum[:]),
+		Body:       string(body),
+	}
+
+	if c.mode == CassetteReplay {
+		return c.replay(req, recorded)
+	}
+	return c.record(req, recorded, body)
+}
+
+func (c *Cassette) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := recorded.Method + " " + recorded.URL + " " + recorded.BodySHA256
+	var matches []Interaction
+	for _, interaction := range c.interactions {
+		r := interaction.Request
+		if r.Method == recorded.Method && r.URL == recorded.URL && r.BodySHA256 == recorded.BodySHA256 {
+			matches = append(matches, interaction)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrCassetteMiss, recorded.Method, recorded.URL)
+	}
+
+	// Повторы одного запроса (например, после 429) отдаются по порядку записи,
+	// последний ответ используется, когда записи закончились
+	index := c.played[key]
+	if index >= len(matches) {
+		index = len(matches) - 1
+	}
+	c.played[key]++
+
+	resp := matches[index].Response
+	return &http.Response{
+		StatusCode: resp.StatusCode,
+		Status:     fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		Header:     resp.Header.Clone(),
+		Body:       io.NopCloser(bytes.NewBufferString(resp.Body)),
+		Request:    req,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+	}, nil
+}
+
+func (c *Cassette) record(req *http.Request, recorded RecordedRequest, body []byte) (*http.Response, error) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.interactions = append(c.interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     recordedHeader(resp.Header),
+			Body:       string(respBody),
+		},
+	})
+
+	// Сохраняем после каждого обмена, чтобы прерванный запуск не терял запись
+	if err := c.save(); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// recordedHeader оставляет только заголовки, которые влияют на обработку ответа
+func recordedHeader(header http.Header) http.Header {
+	kept := http.Header{}
+	for _, key := range []string{"Content-Type", "Retry-After"} {
+		if value := header.Get(key); value != "" {
+			kept.Set(key, value)
+		}
+	}
+	return kept
+}
+
+func (c *Cassette) save() error {
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling cassette: %w", err)
+	}
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("error creating cassette dir: %w", err)
+		}
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("error writing cassette: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Классы ошибок провайдера. Проверяются через errors.Is.
+var (
+	ErrRateLimited     = errors.New("rate limited")
+	ErrServerError     = errors.New("server error")
+	ErrAuth            = errors.New("authentication error")
+	ErrContentFiltered = errors.New("content filtered")
+	ErrContextTooLong  = errors.New("context too long")
+	ErrNetwork         = errors.New("network error")
+)
+
+// Error — классифицированная ошибка обращения к провайдеру
+type Error struct {
+	Kind       error
+	StatusCode int
+	RetryAfter time.Duration
+	Message    string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	var builder strings.Builder
+	builder.WriteString("API error")
+	if e.Kind != nil {
+		builder.WriteString(" (" + e.Kind.Error() + ")")
+	}
+	if e.StatusCode != 0 {
+		builder.WriteString(fmt.Sprintf(": status %d", e.StatusCode))
+	}
+	if e.Message != "" {
+		builder.WriteString(": " + e.Message)
+	}
+	if e.Err != nil {
+		builder.WriteString(": " + e.Err.Error())
+	}
+	return builder.String()
+}
+
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable сообщает, имеет ли смысл повторить запрос после этой ошибки
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, ErrServerError) ||
+		errors.Is(err, ErrNetwork)
+}
+
+// classifyHTTPError превращает неуспешный HTTP ответ в *Error
+func classifyHTTPError(resp *http.Response, body []byte) *Error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Message:    string(body),
+	}
+
+	lowerBody := strings.ToLower(string(body))
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		apiErr.Kind = ErrRateLimited
+		apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		apiErr.Kind = ErrAuth
+	case resp.StatusCode == http.StatusRequestEntityTooLarge || isContextTooLong(lowerBody):
+		apiErr.Kind = ErrContextTooLong
+	case isContentFiltered(lowerBody):
+		apiErr.Kind = ErrContentFiltered
+	case resp.StatusCode >= 500:
+		// Сюда же попадает 529 overloaded у Anthropic
+		apiErr.Kind = ErrServerError
+		apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+
+	return apiErr
+}
+
+// classifyErrorMessage классифицирует ошибку, пришедшую в теле успешного ответа
+func classifyErrorMessage(message string) *Error {
+	apiErr := &Error{Message: message}
+	lowerMessage := strings.ToLower(message)
+
+	switch {
+	case isContextTooLong(lowerMessage):
+		apiErr.Kind = ErrContextTooLong
+	case isContentFiltered(lowerMessage):
+		apiErr.Kind = ErrContentFiltered
+	case strings.Contains(lowerMessage, "rate limit"):
+		apiErr.Kind = ErrRateLimited
+	case strings.Contains(lowerMessage, "overloaded"), strings.Contains(lowerMessage, "internal"):
+		apiErr.Kind = ErrServerError
+	}
+
+	return apiErr
+}
+
+func isContextTooLong(lowerBody string) bool {
+	return strings.Contains(lowerBody, "context_length_exceeded") ||
+		strings.Contains(lowerBody, "maximum context length") ||
+		strings.Contains(lowerBody, "prompt is too long") ||
+		strings.Contains(lowerBody, "context window")
+}
+
+func isContentFiltered(lowerBody string) bool {
+	return strings.Contains(lowerBody, "content_filter") ||
+		strings.Contains(lowerBody, "content management policy") ||
+		strings.Contains(lowerBody, "content_policy")
+}
+
+// parseRetryAfter разбирает заголовок Retry-After в секундах или в формате HTTP-даты
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+
+	return 0
+}
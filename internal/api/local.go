@@ -0,0 +1,35 @@
+package api
+
+const (
+	defaultLocalBaseURL = "http://localhost:11434/v1"
+	defaultLocalModel   = "llama3.1"
+)
+
+// LocalClient работает с локальным сервером Ollama или llama.cpp
+// через их OpenAI-совместимый endpoint. API ключ не обязателен.
+type LocalClient struct {
+	*OpenAIClient
+}
+
+func NewLocalClient(cfg ProviderConfig) *LocalClient {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultLocalBaseURL
+	}
+	if cfg.Model == "" {
+		cfg.Model = defaultLocalModel
+	}
+
+	return &LocalClient{OpenAIClient: NewOpenAIClient(cfg)}
+}
+
+func (c *LocalClient) Name() string {
+	return ProviderLocal
+}
+
+func (c *LocalClient) Capabilities() Capabilities {
+	return Capabilities{
+		SystemPrompt: true,
+		JSONMode:     true,
+		Streaming:    true,
+	}
+}
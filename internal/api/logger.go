@@ -0,0 +1,26 @@
+package api
+
+import (
+	"context"
+	"log"
+)
+
+// Logger получает служебные сообщения провайдеров: о повторах, ошибках кэша
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type loggerKey struct{}
+
+// WithLogger направляет служебные сообщения запросов с этим контекстом в
+// logger. Без него сообщения пишутся в стандартный log.
+func WithLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+func loggerFromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(Logger); ok {
+		return logger
+	}
+	return log.Default()
+}
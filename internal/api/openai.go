@@ -1,18 +1,24 @@
 package api
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"strings"
-	"time"
 )
 
+const (
+	defaultOpenAIBaseURL = "https://openrouter.ai/api/v1"
+	defaultOpenAIModel   = "gpt-4.1-mini"
+)
+
+// OpenAIClient работает с любым OpenAI-совместимым endpoint (OpenAI, OpenRouter, vLLM и т.д.)
 type OpenAIClient struct {
-	apiKey string
-	client *http.Client
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
 }
 
 type OpenAIRequest struct {
@@ -20,6 +26,27 @@ type OpenAIRequest struct {
 	Messages    []Message `json:"messages"`
 	Temperature float64   `json:"temperature"`
 	MaxTokens   int       `json:"max_tokens"`
+
+	ResponseFormat *OpenAIResponseFormat `json:"response_format,omitempty"`
+
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *OpenAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+type OpenAIStreamOptions struct {
+	// IncludeUsage просит прислать usage последним чанком
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type OpenAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *OpenAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type OpenAIJSONSchema struct {
+	Name   string                 `json:"name"`
+	Schema map[string]interface{} `json:"schema"`
+	Strict bool                   `json:"strict"`
 }
 
 type Message struct {
@@ -28,101 +55,182 @@ type Message struct {
 }
 
 type OpenAIResponse struct {
+	Model   string    `json:"model"`
 	Choices []Choice  `json:"choices"`
+	Usage   Usage     `json:"usage"`
 	Error   *APIError `json:"error,omitempty"`
 }
 
 type Choice struct {
-	Message Message `json:"message"`
+	Message      Message `json:"message"`
+	FinishReason string  `json:"finish_reason"`
+}
+
+// OpenAIStreamChunk — одно событие потокового ответа
+type OpenAIStreamChunk struct {
+	Model   string         `json:"model"`
+	Choices []StreamChoice `json:"choices"`
+	Usage   *Usage         `json:"usage,omitempty"`
+	Error   *APIError      `json:"error,omitempty"`
+}
+
+type StreamChoice struct {
+	Delta        Message `json:"delta"`
+	FinishReason string  `json:"finish_reason,omitempty"`
 }
 
 type APIError struct {
 	Message string `json:"message"`
 	Type    string `json:"type"`
-	Code    string `json:"code"`
+	// OpenRouter возвращает числовой код, OpenAI — строковый
+	Code interface{} `json:"code"`
 }
 
-func NewOpenAIClient(apiKey string) *OpenAIClient {
+func NewOpenAIClient(cfg ProviderConfig) *OpenAIClient {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL(ProviderOpenAI)
+	}
+	model := cfg.Model
+	if model == "" {
+		model = DefaultModel(ProviderOpenAI)
+	}
+
 	return &OpenAIClient{
-		apiKey: apiKey,
-		client: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		apiKey:  cfg.APIKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		client:  newHTTPClient(cfg),
 	}
 }
 
-func (c *OpenAIClient) ExtractProfile(prompt string) (string, error) {
-	reqBody := OpenAIRequest{
-		Model: "gpt-4.1-mini",
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Temperature: 0.1, // Низкая температура для точности
-		MaxTokens:   2000,
-	}
-
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("error marshaling request: %w", err)
+func (c *OpenAIClient) Name() string {
+	return ProviderOpenAI
+}
+
+func (c *OpenAIClient) Capabilities() Capabilities {
+	return Capabilities{
+		SystemPrompt: true,
+		JSONMode:     true,
+		JSONSchema:   true,
+		Streaming:    true,
 	}
+}
 
-	req, err := http.NewRequest("POST", "https://openrouter.ai/api/v1/chat/completions", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return "", fmt.Errorf("error creating request: %w", err)
+func (c *OpenAIClient) Complete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = c.model
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("HTTP-Referer", "https://profile-extractor.local")
-	req.Header.Set("X-Title", "Profile Extractor Bot")
+	reqBody := OpenAIRequest{
+		Model:       model,
+		Messages:    req.Messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+	}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("error making request: %w", err)
+	if format := req.ResponseFormat; format != nil {
+		reqBody.ResponseFormat = &OpenAIResponseFormat{Type: format.Type}
+		if format.Type == ResponseFormatJSONSchema {
+			reqBody.ResponseFormat.JSONSchema = &OpenAIJSONSchema{
+				Name:   format.Name,
+				Schema: format.Schema,
+				Strict: format.Strict,
+			}
+		}
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("error reading response: %w", err)
+	headers := map[string]string{}
+	if c.apiKey != "" {
+		headers["Authorization"] = "Bearer " + c.apiKey
+	}
+	// OpenRouter использует эти заголовки для атрибуции приложения
+	if strings.Contains(c.baseURL, "openrouter.ai") {
+		headers["HTTP-Referer"] = "https://profile-extractor.local"
+		headers["X-Title"] = "Profile Extractor Bot"
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+	if req.OnProgress != nil {
+		return c.stream(ctx, headers, reqBody, req.OnProgress)
+	}
+
+	body, err := postJSON(ctx, c.client, c.baseURL+"/chat/completions", headers, reqBody)
+	if err != nil {
+		return nil, err
 	}
 
 	var openAIResp OpenAIResponse
 	if err := json.Unmarshal(body, &openAIResp); err != nil {
-		return "", fmt.Errorf("error unmarshaling response: %w", err)
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
 	}
 
 	if openAIResp.Error != nil {
-		return "", fmt.Errorf("API error: %s", openAIResp.Error.Message)
+		return nil, classifyErrorMessage(openAIResp.Error.Message)
 	}
 
 	if len(openAIResp.Choices) == 0 {
-		return "", fmt.Errorf("no choices returned from API")
+		return nil, fmt.Errorf("no choices returned from API")
 	}
 
-	content := openAIResp.Choices[0].Message.Content
+	if openAIResp.Choices[0].FinishReason == "content_filter" {
+		return nil, &Error{Kind: ErrContentFiltered, Message: "response blocked by content filter"}
+	}
 
-	// Очистка от markdown форматирования
-	content = cleanJSONResponse(content)
+	if openAIResp.Model != "" {
+		model = openAIResp.Model
+	}
 
-	return content, nil
+	return &ChatResponse{
+		Content:      openAIResp.Choices[0].Message.Content,
+		Model:        model,
+		FinishReason: openAIResp.Choices[0].FinishReason,
+		Usage:        openAIResp.Usage,
+	}, nil
 }
 
-// cleanJSONResponse удаляет markdown форматирование из ответа
-func cleanJSONResponse(response string) string {
-	// Удаляем ```json и ``` блоки
-	response = strings.ReplaceAll(response, "```json", "")
-	response = strings.ReplaceAll(response, "```", "")
+// stream выполняет запрос с stream: true и собирает ответ из чанков
+func (c *OpenAIClient) stream(ctx context.Context, headers map[string]string, reqBody OpenAIRequest, progress ProgressFunc) (*ChatResponse, error) {
+	reqBody.Stream = true
+	reqBody.StreamOptions = &OpenAIStreamOptions{IncludeUsage: true}
+
+	result := &ChatResponse{Model: reqBody.Model}
+	acc := newStreamAccumulator(progress)
+
+	err := postStream(ctx, c.client, c.baseURL+"/chat/completions", headers, reqBody, func(data []byte) error {
+		var chunk OpenAIStreamChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("error unmarshaling stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return classifyErrorMessage(chunk.Error.Message)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.Usage = *chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			acc.write(choice.Delta.Content)
+			if choice.FinishReason != "" {
+				result.FinishReason = choice.FinishReason
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	// Убираем лишние пробелы и переносы строк в начале и конце
-	response = strings.TrimSpace(response)
+	if result.FinishReason == "content_filter" {
+		return nil, &Error{Kind: ErrContentFiltered, Message: "response blocked by content filter"}
+	}
+	if acc.content.Len() == 0 && result.FinishReason == "" {
+		return nil, fmt.Errorf("no choices returned from API")
+	}
 
-	return response
+	acc.done(result.Usage.CompletionTokens)
+	result.Content = acc.content.String()
+	return result, nil
 }
@@ -0,0 +1,340 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"profile-extractor/internal/jsonrepair"
+)
+
+// Provider абстрагирует конкретного LLM-вендора от пайплайна извлечения
+type Provider interface {
+	// Name возвращает имя провайдера для логов и метаданных
+	Name() string
+	// Complete выполняет один chat completion запрос.
+	// Отмена ctx прерывает запрос.
+	Complete(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+	// Capabilities сообщает, что умеет провайдер
+	Capabilities() Capabilities
+}
+
+// ChatRequest описывает запрос к модели независимо от формата вендора
+type ChatRequest struct {
+	Model       string
+	Messages    []Message
+	Temperature float64
+	MaxTokens   int
+	// ResponseFormat включает нативный JSON режим, если провайдер его поддерживает
+	ResponseFormat *ResponseFormat
+	// OnProgress включает потоковый ответ; задается только для провайдеров
+	// с Capabilities().Streaming
+	OnProgress ProgressFunc
+}
+
+const (
+	ResponseFormatJSONObject = "json_object"
+	ResponseFormatJSONSchema = "json_schema"
+)
+
+// ResponseFormat описывает требуемый формат ответа модели
+type ResponseFormat struct {
+	Type   string
+	Name   string
+	Schema map[string]interface{}
+	Strict bool
+}
+
+// JSONSchemaFormat создает формат ответа со структурой из JSON Schema
+func JSONSchemaFormat(name string, schema map[string]interface{}) *ResponseFormat {
+	return &ResponseFormat{
+		Type:   ResponseFormatJSONSchema,
+		Name:   name,
+		Schema: schema,
+	}
+}
+
+// ChatResponse содержит ответ модели и статистику использования токенов
+type ChatResponse struct {
+	Content      string
+	Model        string
+	FinishReason string
+	Usage        Usage
+	// Cached — ответ взят из кэша, токены не тратились
+	Cached bool `json:"-"`
+}
+
+// Usage — количество токенов, потраченных на запрос
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Add прибавляет usage другого запроса
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+}
+
+// Capabilities описывает поддерживаемые провайдером возможности
+type Capabilities struct {
+	SystemPrompt bool
+	JSONMode     bool
+	JSONSchema   bool
+	Streaming    bool
+}
+
+// ModelParams — параметры модели для одного этапа пайплайна
+type ModelParams struct {
+	Model       string  `yaml:"model" json:"model"`
+	Temperature float64 `yaml:"temperature" json:"temperature"`
+	MaxTokens   int     `yaml:"max_tokens" json:"max_tokens"`
+}
+
+// ProviderConfig задает выбор провайдера и параметры подключения
+type ProviderConfig struct {
+	Type    string `yaml:"type"`
+	BaseURL string `yaml:"base_url"`
+	APIKey  string `yaml:"api_key"`
+	Model   string `yaml:"model"`
+	// Timeout ограничивает один HTTP запрос; дедлайн ctx может быть короче
+	Timeout time.Duration `yaml:"timeout"`
+	// Transport подменяет HTTP транспорт, например кассетой для офлайн запуска
+	Transport http.RoundTripper `yaml:"-"`
+}
+
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderLocal     = "local"
+)
+
+// DefaultBaseURL возвращает endpoint по умолчанию для типа провайдера
+func DefaultBaseURL(providerType string) string {
+	switch strings.ToLower(providerType) {
+	case ProviderAnthropic:
+		return defaultAnthropicBaseURL
+	case ProviderLocal, "ollama", "llamacpp":
+		return defaultLocalBaseURL
+	default:
+		return defaultOpenAIBaseURL
+	}
+}
+
+// DefaultModel возвращает модель по умолчанию для типа провайдера
+func DefaultModel(providerType string) string {
+	switch strings.ToLower(providerType) {
+	case ProviderAnthropic:
+		return defaultAnthropicModel
+	case ProviderLocal, "ollama", "llamacpp":
+		return defaultLocalModel
+	default:
+		return defaultOpenAIModel
+	}
+}
+
+// NewProvider создает провайдера по конфигурации
+func NewProvider(cfg ProviderConfig) (Provider, error) {
+	switch strings.ToLower(cfg.Type) {
+	case "", ProviderOpenAI, "openrouter":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("API key is required for provider %q", ProviderOpenAI)
+		}
+		return NewOpenAIClient(cfg), nil
+	case ProviderAnthropic:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("API key is required for provider %q", ProviderAnthropic)
+		}
+		return NewAnthropicClient(cfg), nil
+	case ProviderLocal, "ollama", "llamacpp":
+		return NewLocalClient(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown provider type: %q", cfg.Type)
+	}
+}
+
+// maxContinuations ограничивает число запросов на продолжение оборванного ответа
+const maxContinuations = 2
+
+// continuationPrompt просит модель продолжить ответ, оборванный по max_tokens
+const continuationPrompt = "Ответ оборвался. Продолжи JSON ровно с того места, где остановился, без повторов, пояснений и markdown."
+
+// ExtractResult — результат одного этапа извлечения
+type ExtractResult struct {
+	JSON  string
+	Model string
+	// Repairs — исправления, которые понадобились, чтобы получить валидный JSON
+	Repairs []string
+	// Continuations — сколько раз модель просили продолжить оборванный ответ
+	Continuations int
+	// CachedCalls — сколько ответов взято из кэша
+	CachedCalls int
+	Usage       Usage
+}
+
+// ExtractProfile отправляет промпт провайдеру с параметрами этапа и возвращает
+// валидный JSON. Оборванный по max_tokens ответ дозапрашивается у модели,
+// синтаксические ошибки исправляются через jsonrepair. Если в ctx задан
+// WithProgress, ответ запрашивается потоком.
+// format может быть nil — тогда формат JSON задается только текстом промпта.
+func ExtractProfile(ctx context.Context, p Provider, params ModelParams, prompt string, format *ResponseFormat) (*ExtractResult, error) {
+	req := ChatRequest{
+		Model: params.Model,
+		Messages: []Message{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+		Temperature:    params.Temperature,
+		MaxTokens:      params.MaxTokens,
+		ResponseFormat: negotiateResponseFormat(p.Capabilities(), format),
+	}
+	if p.Capabilities().Streaming {
+		req.OnProgress = progressFromContext(ctx)
+	}
+
+	resp, err := p.Complete(ctx, req)
+	if err != nil && req.ResponseFormat != nil && isResponseFormatRejected(err) {
+		// Модель за endpoint может не поддерживать response_format,
+		// хотя сам API его принимает — повторяем только с промптом
+		req.ResponseFormat = nil
+		resp, err = p.Complete(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExtractResult{Model: resp.Model, Usage: resp.Usage}
+	result.countCached(resp)
+	content := resp.Content
+
+	for resp.FinishReason == "length" && result.Continuations < maxContinuations {
+		result.Continuations++
+
+		// Продолжение не может начинаться с "{", поэтому схему не передаем
+		req.ResponseFormat = nil
+		req.Messages = append(req.Messages,
+			Message{Role: "assistant", Content: resp.Content},
+			Message{Role: "user", Content: continuationPrompt},
+		)
+
+		resp, err = p.Complete(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("error continuing truncated response: %w", err)
+		}
+		content += resp.Content
+		result.Usage.Add(resp.Usage)
+		result.countCached(resp)
+	}
+
+	repaired, repairs, err := jsonrepair.Repair(content)
+	if err != nil {
+		return nil, fmt.Errorf("model returned invalid JSON: %w", err)
+	}
+	if resp.FinishReason == "length" {
+		repairs = append(repairs, "response still truncated after continuation")
+	}
+
+	result.JSON = repaired
+	result.Repairs = repairs
+
+	return result, nil
+}
+
+func (r *ExtractResult) countCached(resp *ChatResponse) {
+	if resp.Cached {
+		r.CachedCalls++
+	}
+}
+
+// negotiateResponseFormat понижает формат до того, что умеет провайдер:
+// json_schema → json_object → только промпт
+func negotiateResponseFormat(caps Capabilities, format *ResponseFormat) *ResponseFormat {
+	if format == nil {
+		return nil
+	}
+
+	switch {
+	case format.Type == ResponseFormatJSONSchema && caps.JSONSchema:
+		return format
+	case caps.JSONMode:
+		return &ResponseFormat{Type: ResponseFormatJSONObject}
+	default:
+		return nil
+	}
+}
+
+func isResponseFormatRejected(err error) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	message := strings.ToLower(apiErr.Message)
+	return strings.Contains(message, "response_format") ||
+		strings.Contains(message, "json_schema") ||
+		strings.Contains(message, "structured output")
+}
+
+func newHTTPClient(cfg ProviderConfig) *http.Client {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: cfg.Transport,
+	}
+}
+
+// postJSON отправляет JSON тело и возвращает сырой ответ, если статус 200.
+// Неуспешные ответы и сетевые сбои возвращаются как *Error.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}) ([]byte, error) {
+	jsonBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Отмену не считаем сетевым сбоем, иначе ее начнут повторять
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// Отсутствие записи в кассете не исправится повтором
+		if errors.Is(err, ErrCassetteMiss) {
+			return nil, err
+		}
+		return nil, &Error{Kind: ErrNetwork, Message: "error making request", Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &Error{Kind: ErrNetwork, Message: "error reading response", Err: err}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyHTTPError(resp, body)
+	}
+
+	return body, nil
+}
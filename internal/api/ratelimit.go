@@ -0,0 +1,223 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// RateLimitConfig задает клиентские лимиты запросов к провайдеру. 0 — без ограничения.
+type RateLimitConfig struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	TokensPerMinute   int `yaml:"tokens_per_minute"`
+	// MaxConcurrent ограничивает число одновременных запросов
+	MaxConcurrent int `yaml:"max_concurrent"`
+}
+
+// RateLimiter ограничивает RPM, TPM и число одновременных запросов.
+// Один лимитер можно разделять между провайдерами и горутинами.
+type RateLimiter struct {
+	sem chan struct{}
+
+	mu       sync.Mutex
+	requests *bucket
+	tokens   *bucket
+
+	// now и sleep заменяются в тестах, чтобы не ждать реальные минуты
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// bucket — token bucket, который полностью пополняется за минуту
+type bucket struct {
+	capacity  float64
+	available float64
+	updated   time.Time
+}
+
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity:  float64(perMinute),
+		available: float64(perMinute),
+		updated:   now,
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated)
+	b.updated = now
+	b.available += b.capacity * elapsed.Minutes()
+	if b.available > b.capacity {
+		b.available = b.capacity
+	}
+}
+
+// wait возвращает, сколько ждать, пока в bucket появится amount
+func (b *bucket) wait(amount float64) time.Duration {
+	if b.available >= amount {
+		return 0
+	}
+	return time.Duration((amount - b.available) / b.capacity * float64(time.Minute))
+}
+
+// NewRateLimiter создает лимитер. Если ни один лимит не задан, возвращает nil.
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	if cfg.RequestsPerMinute <= 0 && cfg.TokensPerMinute <= 0 && cfg.MaxConcurrent <= 0 {
+		return nil
+	}
+
+	return newRateLimiter(cfg, time.Now, sleepContext)
+}
+
+func newRateLimiter(cfg RateLimitConfig, now func() time.Time, sleep func(context.Context, time.Duration) error) *RateLimiter {
+	limiter := &RateLimiter{
+		requests: newBucket(cfg.RequestsPerMinute, now()),
+		tokens:   newBucket(cfg.TokensPerMinute, now()),
+		now:      now,
+		sleep:    sleep,
+	}
+	if cfg.MaxConcurrent > 0 {
+		limiter.sem = make(chan struct{}, cfg.MaxConcurrent)
+	}
+	return limiter
+}
+
+// Acquire ждет слот и бюджет на запрос стоимостью tokens. Возвращенную
+// функцию нужно вызвать после ответа с фактическим числом токенов
+// (0, если оно неизвестно), чтобы вернуть в бюджет переоценку.
+func (l *RateLimiter) Acquire(ctx context.Context, tokens int) (func(actualTokens int), error) {
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	reserved, err := l.reserve(ctx, tokens)
+	if err != nil {
+		l.releaseSlot()
+		return nil, err
+	}
+
+	var once sync.Once
+	return func(actualTokens int) {
+		once.Do(func() {
+			if l.tokens != nil && actualTokens > 0 {
+				l.mu.Lock()
+				// Разница может быть отрицательной: перерасход становится долгом
+				l.tokens.available += reserved - float64(actualTokens)
+				l.mu.Unlock()
+			}
+			l.releaseSlot()
+		})
+	}, nil
+}
+
+func (l *RateLimiter) reserve(ctx context.Context, tokens int) (float64, error) {
+	amount := float64(tokens)
+	if l.tokens != nil && amount > l.tokens.capacity {
+		// Запрос больше минутного бюджета иначе не дождется своей очереди
+		amount = l.tokens.capacity
+	}
+
+	for {
+		l.mu.Lock()
+		now := l.now()
+		var delay time.Duration
+		if l.requests != nil {
+			l.requests.refill(now)
+			delay = l.requests.wait(1)
+		}
+		if l.tokens != nil {
+			l.tokens.refill(now)
+			if wait := l.tokens.wait(amount); wait > delay {
+				delay = wait
+			}
+		}
+		if delay == 0 {
+			if l.requests != nil {
+				l.requests.available--
+			}
+			if l.tokens != nil {
+				l.tokens.available -= amount
+			}
+			l.mu.Unlock()
+			return amount, nil
+		}
+		l.mu.Unlock()
+
+		if err := l.sleep(ctx, delay); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// sleepContext ждет d или отмены контекста
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *RateLimiter) releaseSlot() {
+	if l.sem != nil {
+		<-l.sem
+	}
+}
+
+// EstimateTokens грубо оценивает число токенов в тексте. Кириллица занимает
+// больше токенов на символ, чем латиница, поэтому оценка берется по рунам.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 2) / 3
+}
+
+// EstimateRequestTokens оценивает стоимость запроса для лимита TPM: провайдеры
+// резервируют под ответ весь max_tokens
+func EstimateRequestTokens(req ChatRequest) int {
+	tokens := req.MaxTokens
+	for _, msg := range req.Messages {
+		// Несколько токенов уходит на служебную разметку сообщения
+		tokens += EstimateTokens(msg.Content) + 4
+	}
+	return tokens
+}
+
+// RateLimitedProvider ждет бюджета лимитера перед каждым запросом
+type RateLimitedProvider struct {
+	Provider
+	limiter *RateLimiter
+}
+
+// WithRateLimit оборачивает провайдера лимитером. Оборачивать нужно до WithRetry,
+// чтобы повторы тоже расходовали бюджет.
+func WithRateLimit(p Provider, limiter *RateLimiter) Provider {
+	if limiter == nil {
+		return p
+	}
+	return &RateLimitedProvider{Provider: p, limiter: limiter}
+}
+
+func (r *RateLimitedProvider) Complete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	release, err := r.limiter.Acquire(ctx, EstimateRequestTokens(req))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.Provider.Complete(ctx, req)
+	if err != nil {
+		release(0)
+		return nil, err
+	}
+	release(resp.Usage.TotalTokens)
+	return resp, nil
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock — часы лимитера, которые двигает только sleep
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func newTestLimiter(cfg RateLimitConfig) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	return newRateLimiter(cfg, clock.Now, clock.Sleep), clock
+}
+
+func acquire(t *testing.T, l *RateLimiter, tokens int) func(int) {
+	t.Helper()
+	release, err := l.Acquire(context.Background(), tokens)
+	if err != nil {
+		t.Fatalf("Acquire(%d): %v", tokens, err)
+	}
+	return release
+}
+
+func assertSleeps(t *testing.T, clock *fakeClock, want ...time.Duration) {
+	t.Helper()
+	if !reflect.DeepEqual(clock.sleeps, want) {
+		t.Errorf("sleeps = %v, want %v", clock.sleeps, want)
+	}
+	clock.sleeps = nil
+}
+
+func TestRateLimiterRPM(t *testing.T) {
+	l, clock := newTestLimiter(RateLimitConfig{RequestsPerMinute: 2})
+
+	acquire(t, l, 0)(0)
+	acquire(t, l, 0)(0)
+	assertSleeps(t, clock)
+
+	// Бюджет исчерпан: один запрос пополняется за полминуты
+	acquire(t, l, 0)(0)
+	assertSleeps(t, clock, 30*time.Second)
+
+	// Через минуту бюджет полон, но не больше capacity
+	clock.now = clock.now.Add(5 * time.Minute)
+	acquire(t, l, 0)(0)
+	acquire(t, l, 0)(0)
+	acquire(t, l, 0)(0)
+	assertSleeps(t, clock, 30*time.Second)
+}
+
+func TestRateLimiterTPMReservationOverCapacity(t *testing.T) {
+	l, clock := newTestLimiter(RateLimitConfig{TokensPerMinute: 100})
+
+	acquire(t, l, 60)(0)
+	assertSleeps(t, clock)
+
+	// Запрос больше минутного бюджета резервирует весь бюджет и ждет только
+	// недостающие 60 токенов, а не вечно
+	acquire(t, l, 250)(0)
+	assertSleeps(t, clock, 36*time.Second)
+	if l.tokens.available != 0 {
+		t.Errorf("available = %v, want 0", l.tokens.available)
+	}
+
+	acquire(t, l, 50)(0)
+	assertSleeps(t, clock, 30*time.Second)
+}
+
+func TestRateLimiterReleaseOverEstimate(t *testing.T) {
+	l, clock := newTestLimiter(RateLimitConfig{TokensPerMinute: 100})
+
+	// Оценка 80, потрачено 20: 60 токенов возвращаются в бюджет
+	acquire(t, l, 80)(20)
+	if l.tokens.available != 80 {
+		t.Fatalf("available = %v, want 80", l.tokens.available)
+	}
+	acquire(t, l, 80)(80)
+	assertSleeps(t, clock)
+
+	// Release без фактического числа токенов ничего не возвращает
+	clock.now = clock.now.Add(time.Minute)
+	acquire(t, l, 80)(0)
+	if l.tokens.available != 20 {
+		t.Errorf("available = %v, want 20", l.tokens.available)
+	}
+}
+
+func TestRateLimiterReleaseDebt(t *testing.T) {
+	l, clock := newTestLimiter(RateLimitConfig{TokensPerMinute: 100})
+
+	// Перерасход становится долгом, который следующий запрос отрабатывает ожиданием
+	release := acquire(t, l, 50)
+	release(150)
+	release(150)
+	if l.tokens.available != -50 {
+		t.Fatalf("available = %v, want -50 after a single release", l.tokens.available)
+	}
+
+	acquire(t, l, 10)(0)
+	assertSleeps(t, clock, 36*time.Second)
+}
+
+func TestRateLimiterCancelWhileWaiting(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	waiting := make(chan time.Duration, 1)
+	l := newRateLimiter(RateLimitConfig{RequestsPerMinute: 1, MaxConcurrent: 1}, clock.Now,
+		func(ctx context.Context, d time.Duration) error {
+			waiting <- d
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	acquire(t, l, 0)(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := l.Acquire(ctx, 0)
+		done <- err
+	}()
+
+	if d := <-waiting; d != time.Minute {
+		t.Errorf("wait = %v, want 1m", d)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire error = %v, want context.Canceled", err)
+	}
+
+	// Отмененный запрос не занимает слот и не расходует бюджет
+	if len(l.sem) != 0 {
+		t.Errorf("slots in use = %d, want 0", len(l.sem))
+	}
+	if l.requests.available != 0 {
+		t.Errorf("requests available = %v, want 0", l.requests.available)
+	}
+}
+
+func TestRateLimiterCancelWhileWaitingForSlot(t *testing.T) {
+	l, _ := newTestLimiter(RateLimitConfig{MaxConcurrent: 1})
+
+	release := acquire(t, l, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.Acquire(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire error = %v, want context.Canceled", err)
+	}
+
+	release(0)
+	acquire(t, l, 0)(0)
+}
+
+func TestSleepContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sleepContext(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("sleepContext error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy задает число попыток и экспоненциальную задержку между ними
+type RetryPolicy struct {
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	Multiplier     float64       `yaml:"multiplier"`
+	// Jitter — доля задержки (0..1), на которую она случайно уменьшается
+	Jitter float64 `yaml:"jitter"`
+}
+
+// DefaultRetryPolicy возвращает политику повторов по умолчанию
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// Backoff возвращает задержку перед повтором номер attempt (начиная с 1)
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay -= delay * jitter * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+// RetryingProvider повторяет запросы при временных ошибках провайдера
+type RetryingProvider struct {
+	Provider
+	policy RetryPolicy
+}
+
+// WithRetry оборачивает провайдера политикой повторов
+func WithRetry(p Provider, policy RetryPolicy) Provider {
+	if policy.MaxAttempts <= 1 {
+		return p
+	}
+	return &RetryingProvider{Provider: p, policy: policy}
+}
+
+func (r *RetryingProvider) Complete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	var lastErr error
+
+	for attempt := 1; attempt <= r.policy.MaxAttempts; attempt++ {
+		resp, err := r.Provider.Complete(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		lastErr = err
+		if !IsRetryable(err) || attempt == r.policy.MaxAttempts {
+			break
+		}
+
+		delay := r.policy.Backoff(attempt)
+		// Retry-After от сервера важнее нашей оценки
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+			delay = apiErr.RetryAfter
+		}
+
+		loggerFromContext(ctx).Printf("Retrying %s request (attempt %d/%d) in %v: %v",
+			r.Provider.Name(), attempt+1, r.policy.MaxAttempts, delay.Round(time.Millisecond), err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return nil, lastErr
+}
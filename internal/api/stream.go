@@ -0,0 +1,190 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Progress — состояние потокового ответа модели
+type Progress struct {
+	// Tokens — полученные токены ответа; до итогового usage это оценка
+	Tokens  int
+	Elapsed time.Duration
+	// Done — ответ получен целиком
+	Done bool
+}
+
+// ProgressFunc получает обновления прогресса. Вызывается из горутины запроса,
+// поэтому не должна блокироваться надолго.
+type ProgressFunc func(Progress)
+
+type progressKey struct{}
+
+// WithProgress включает потоковый ответ для запросов ExtractProfile с этим
+// контекстом, если провайдер поддерживает streaming
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func progressFromContext(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// ProgressChannel адаптирует прогресс к каналу, например для передачи клиентам
+// HTTP сервера. Если читатель отстает, промежуточные обновления пропускаются;
+// итоговое ждет читателя, пока не отменен ctx.
+func ProgressChannel(ctx context.Context, ch chan<- Progress) ProgressFunc {
+	return func(p Progress) {
+		if p.Done {
+			select {
+			case ch <- p:
+			case <-ctx.Done():
+			}
+			return
+		}
+		select {
+		case ch <- p:
+		default:
+		}
+	}
+}
+
+// streamAccumulator собирает текст ответа из чанков и сообщает прогресс
+type streamAccumulator struct {
+	content  strings.Builder
+	start    time.Time
+	progress ProgressFunc
+}
+
+func newStreamAccumulator(progress ProgressFunc) *streamAccumulator {
+	return &streamAccumulator{start: time.Now(), progress: progress}
+}
+
+func (a *streamAccumulator) write(delta string) {
+	if delta == "" {
+		return
+	}
+	a.content.WriteString(delta)
+	a.progress(Progress{
+		Tokens:  EstimateTokens(a.content.String()),
+		Elapsed: time.Since(a.start),
+	})
+}
+
+func (a *streamAccumulator) done(completionTokens int) {
+	if completionTokens == 0 {
+		completionTokens = EstimateTokens(a.content.String())
+	}
+	a.progress(Progress{
+		Tokens:  completionTokens,
+		Elapsed: time.Since(a.start),
+		Done:    true,
+	})
+}
+
+// postStream отправляет запрос с потоковым ответом и передает onData
+// содержимое каждого события "data:" SSE. Таймаут клиента ограничивает паузу
+// между событиями, а не весь ответ — длинная генерация не обрывается.
+func postStream(ctx context.Context, client *http.Client, url string, headers map[string]string,
+	payload interface{}, onData func(data []byte) error) error {
+	jsonBody, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	idleTimeout := client.Timeout
+	streamClient := *client
+	streamClient.Timeout = 0
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var stalled atomic.Bool
+	if idleTimeout > 0 {
+		timer := time.AfterFunc(idleTimeout, func() {
+			stalled.Store(true)
+			cancel()
+		})
+		defer timer.Stop()
+		// Каждая строка потока откладывает таймаут
+		onData = resetOnData(timer, idleTimeout, onData)
+	}
+
+	req, err := http.NewRequestWithContext(streamCtx, "POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	streamErr := func(message string, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if stalled.Load() {
+			return &Error{Kind: ErrNetwork, Message: fmt.Sprintf("stream stalled for %v", idleTimeout)}
+		}
+		return &Error{Kind: ErrNetwork, Message: message, Err: err}
+	}
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		// Отсутствие записи в кассете не исправится повтором
+		if ctx.Err() == nil && errors.Is(err, ErrCassetteMiss) {
+			return err
+		}
+		return streamErr("error making request", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return streamErr("error reading response", err)
+		}
+		return classifyHTTPError(resp, body)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			// Комментарии, event: и пустые строки-разделители
+			continue
+		}
+		data := bytes.TrimSpace(line[len("data:"):])
+		if bytes.Equal(data, []byte("[DONE]")) {
+			return nil
+		}
+		if err := onData(data); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return streamErr("error reading stream", err)
+	}
+
+	return nil
+}
+
+func resetOnData(timer *time.Timer, timeout time.Duration, onData func([]byte) error) func([]byte) error {
+	return func(data []byte) error {
+		timer.Reset(timeout)
+		return onData(data)
+	}
+}
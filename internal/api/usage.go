@@ -0,0 +1,135 @@
+package api
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Pricing — цена модели в долларах США за миллион токенов
+type Pricing struct {
+	Prompt     float64 `yaml:"prompt" json:"prompt"`
+	Completion float64 `yaml:"completion" json:"completion"`
+}
+
+// PriceTable — цены по имени модели
+type PriceTable map[string]Pricing
+
+// Lookup находит цену модели. Имена вида "openai/gpt-4.1" (OpenRouter) и
+// "gpt-4.1-2025-04-14" (датированные версии) сводятся к ключу таблицы.
+func (t PriceTable) Lookup(model string) (Pricing, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+
+	if slash := strings.LastIndex(model, "/"); slash >= 0 {
+		model = model[slash+1:]
+		if price, ok := t[model]; ok {
+			return price, true
+		}
+	}
+
+	// Самый длинный ключ-префикс, чтобы gpt-4.1-mini-... не попал в gpt-4.1
+	best := ""
+	for key := range t {
+		if strings.HasPrefix(model, key+"-") && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best == "" {
+		return Pricing{}, false
+	}
+	return t[best], true
+}
+
+// Cost оценивает стоимость запроса. false означает, что цены модели нет в таблице.
+func (t PriceTable) Cost(model string, usage Usage) (float64, bool) {
+	price, ok := t.Lookup(model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1e6, true
+}
+
+// StageUsage — суммарное потребление токенов и стоимость этапа
+type StageUsage struct {
+	Calls int `json:"calls"`
+	// CachedCalls — ответы из кэша, не вошедшие в calls и стоимость
+	CachedCalls int `json:"cached_calls,omitempty"`
+	Usage
+	CostUSD float64 `json:"cost_usd"`
+	// UnpricedModels — модели без цены в таблице; их стоимость не учтена
+	UnpricedModels []string `json:"unpriced_models,omitempty"`
+}
+
+func (s *StageUsage) add(calls, cached int, model string, usage Usage, prices PriceTable) {
+	s.Calls += calls
+	s.CachedCalls += cached
+	s.Usage.Add(usage)
+	if calls == 0 {
+		return
+	}
+
+	cost, ok := prices.Cost(model, usage)
+	if ok {
+		s.CostUSD += cost
+		return
+	}
+	for _, unpriced := range s.UnpricedModels {
+		if unpriced == model {
+			return
+		}
+	}
+	s.UnpricedModels = append(s.UnpricedModels, model)
+}
+
+// UsageReport собирает потребление токенов по этапам за один запуск.
+// Record можно вызывать из нескольких горутин.
+type UsageReport struct {
+	Stages map[string]*StageUsage `json:"stages"`
+	Total  StageUsage             `json:"total"`
+
+	mu     sync.Mutex
+	prices PriceTable
+	order  []string
+}
+
+// NewUsageReport создает пустой отчет с таблицей цен
+func NewUsageReport(prices PriceTable) *UsageReport {
+	return &UsageReport{
+		Stages: make(map[string]*StageUsage),
+		prices: prices,
+	}
+}
+
+// Record учитывает результат этапа, включая запросы на продолжение ответа.
+// Ответы из кэша считаются отдельно и не входят в стоимость.
+func (r *UsageReport) Record(stage string, result *ExtractResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stageUsage, ok := r.Stages[stage]
+	if !ok {
+		stageUsage = &StageUsage{}
+		r.Stages[stage] = stageUsage
+		r.order = append(r.order, stage)
+	}
+
+	calls := 1 + result.Continuations - result.CachedCalls
+	stageUsage.add(calls, result.CachedCalls, result.Model, result.Usage, r.prices)
+	r.Total.add(calls, result.CachedCalls, result.Model, result.Usage, r.prices)
+}
+
+// StageNames возвращает этапы в порядке первого учета
+func (r *UsageReport) StageNames() []string {
+	if len(r.order) == len(r.Stages) {
+		return r.order
+	}
+	// Отчет, восстановленный из JSON, не знает исходного порядка
+	names := make([]string, 0, len(r.Stages))
+	for name := range r.Stages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"profile-extractor/internal/api"
+	"profile-extractor/internal/interview"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config — настройки приложения из config.yaml и переменных окружения
+type Config struct {
+	Provider api.ProviderConfig `yaml:"provider"`
+	Stages   Stages             `yaml:"stages"`
+	Retry    api.RetryPolicy    `yaml:"retry"`
+	// RateLimit — клиентские лимиты, общие для всех этапов
+	RateLimit api.RateLimitConfig `yaml:"rate_limit"`
+	Repair    RepairConfig        `yaml:"repair"`
+	Chunking  ChunkingConfig      `yaml:"chunking"`
+	// Extraction выбирает, как интервью делится на запросы извлечения
+	Extraction ExtractionConfig `yaml:"extraction"`
+	Cache      api.CacheConfig  `yaml:"cache"`
+	// Cassette записывает или воспроизводит HTTP обмен с провайдером
+	Cassette api.CassetteConfig `yaml:"cassette"`
+	// Pricing — цены моделей для оценки стоимости запуска
+	Pricing api.PriceTable `yaml:"pricing"`
+	// StructuredOutput включает response_format с JSON Schema из словаря,
+	// если провайдер это поддерживает
+	StructuredOutput bool `yaml:"structured_output"`
+	// Stream запрашивает ответ потоком и показывает прогресс генерации
+	Stream bool `yaml:"stream"`
+	// InterviewTimeout ограничивает обработку одного интервью; 0 — без ограничения
+	InterviewTimeout time.Duration `yaml:"interview_timeout"`
+	// OutputDir — каталог для profile_<id>.json и отчетов пакетной обработки
+	OutputDir string `yaml:"output_dir"`
+}
+
+// Stages — параметры модели для каждого этапа пайплайна
+type Stages struct {
+	Extraction StageConfig `yaml:"extraction"`
+	Validation StageConfig `yaml:"validation"`
+}
+
+// RepairConfig управляет циклом исправления профиля по ошибкам валидатора
+type RepairConfig struct {
+	// MaxAttempts — число попыток исправления; 0 отключает цикл
+	MaxAttempts int `yaml:"max_attempts"`
+}
+
+const (
+	// ExtractionWhole — все интервью со всей схемой (с делением по токенам)
+	ExtractionWhole = "whole"
+	// ExtractionBlocks — каждый блок только с разделами схемы из block_sections
+	ExtractionBlocks = "blocks"
+)
+
+// ExtractionConfig задает режим извлечения и соответствие блоков разделам схемы
+type ExtractionConfig struct {
+	Mode string `yaml:"mode"`
+	// TextMethod — способ построения текста интервью, имя из реестра interview.RegisterTextBuilder
+	TextMethod string `yaml:"text_method"`
+	// Workers — сколько запросов извлечения выполняется одновременно
+	Workers int `yaml:"workers"`
+	// BlockSections — разделы схемы для каждого блока интервью по block_name
+	BlockSections map[string][]string `yaml:"block_sections"`
+	// CommonSections отправляются с каждым блоком (базовая информация, теги)
+	CommonSections []string `yaml:"common_sections"`
+}
+
+// SectionsForBlock возвращает разделы схемы для блока. Блок без записи
+// в block_sections получает всю схему.
+func (e ExtractionConfig) SectionsForBlock(blockName string) ([]string, bool) {
+	sections, ok := e.BlockSections[blockName]
+	if !ok {
+		return []string{"*"}, false
+	}
+	return append(append([]string{}, e.CommonSections...), sections...), true
+}
+
+// ChunkingConfig управляет делением длинных интервью на части
+type ChunkingConfig struct {
+	// MaxTokens — бюджет текста интервью на одну часть; 0 отключает деление
+	MaxTokens int `yaml:"max_tokens"`
+}
+
+// StageConfig — параметры этапа. Пустые model и base_url наследуются из provider.
+type StageConfig struct {
+	api.ModelParams `yaml:",inline"`
+	BaseURL         string `yaml:"base_url" json:"endpoint"`
+}
+
+// Default возвращает конфигурацию со значениями по умолчанию
+func Default() *Config {
+	defaultStage := StageConfig{
+		ModelParams: api.ModelParams{
+			Temperature: 0.1, // Низкая температура для точности
+			MaxTokens:   2000,
+		},
+	}
+
+	return &Config{
+		Stages: Stages{
+			Extraction: defaultStage,
+			Validation: defaultStage,
+		},
+		Retry:            api.DefaultRetryPolicy(),
+		Repair:           RepairConfig{MaxAttempts: 2},
+		Chunking:         ChunkingConfig{MaxTokens: 6000},
+		Extraction:       ExtractionConfig{Mode: ExtractionWhole, TextMethod: interview.DefaultTextBuilder, Workers: 4},
+		Cache:            api.DefaultCacheConfig(),
+		StructuredOutput: true,
+		Stream:           true,
+		OutputDir:        "output",
+	}
+}
+
+// Load читает конфигурацию из YAML файла и применяет переопределения из окружения.
+// Отсутствующий файл не является ошибкой — используются значения по умолчанию.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("error parsing config: %w", err)
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	cfg.ResolveStages()
+
+	return cfg, nil
+}
+
+// StageProvider возвращает настройки провайдера с учетом endpoint этапа
+func (c *Config) StageProvider(stage StageConfig) api.ProviderConfig {
+	providerCfg := c.Provider
+	providerCfg.BaseURL = stage.BaseURL
+	providerCfg.Model = stage.Model
+	return providerCfg
+}
+
+// applyEnv переопределяет значения из переменных окружения
+func (c *Config) applyEnv() error {
+	setString(&c.Provider.Type, "LLM_PROVIDER")
+	setString(&c.Provider.BaseURL, "LLM_BASE_URL")
+	setString(&c.Provider.Model, "LLM_MODEL")
+	setString(&c.Provider.APIKey, "LLM_API_KEY")
+
+	// Ключ конкретного вендора используется, если общий ключ не задан
+	if c.Provider.APIKey == "" {
+		switch strings.ToLower(c.Provider.Type) {
+		case api.ProviderAnthropic:
+			c.Provider.APIKey = os.Getenv("ANTHROPIC_API_KEY")
+		case api.ProviderLocal, "ollama", "llamacpp":
+			// Локальным серверам ключ не нужен
+		default:
+			c.Provider.APIKey = os.Getenv("OPENAI_API_KEY")
+		}
+	}
+
+	if err := setInt(&c.Retry.MaxAttempts, "RETRY_MAX_ATTEMPTS"); err != nil {
+		return err
+	}
+
+	if err := setInt(&c.RateLimit.RequestsPerMinute, "RATE_LIMIT_RPM"); err != nil {
+		return err
+	}
+	if err := setInt(&c.RateLimit.TokensPerMinute, "RATE_LIMIT_TPM"); err != nil {
+		return err
+	}
+	if err := setInt(&c.RateLimit.MaxConcurrent, "MAX_CONCURRENT_REQUESTS"); err != nil {
+		return err
+	}
+
+	setString(&c.Extraction.Mode, "EXTRACTION_MODE")
+	setString(&c.Extraction.TextMethod, "EXTRACTION_TEXT_METHOD")
+	if err := setInt(&c.Extraction.Workers, "EXTRACTION_WORKERS"); err != nil {
+		return err
+	}
+
+	if err := setInt(&c.Chunking.MaxTokens, "CHUNK_MAX_TOKENS"); err != nil {
+		return err
+	}
+
+	if err := setInt(&c.Repair.MaxAttempts, "REPAIR_MAX_ATTEMPTS"); err != nil {
+		return err
+	}
+
+	if value := os.Getenv("LLM_STREAM"); value != "" {
+		stream, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid LLM_STREAM: %w", err)
+		}
+		c.Stream = stream
+	}
+
+	setString(&c.Cassette.Mode, "LLM_CASSETTE_MODE")
+	setString(&c.Cassette.Path, "LLM_CASSETTE")
+
+	setString(&c.Cache.Dir, "CACHE_DIR")
+	if value := os.Getenv("CACHE_ENABLED"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid CACHE_ENABLED: %w", err)
+		}
+		c.Cache.Enabled = enabled
+	}
+
+	if value := os.Getenv("INTERVIEW_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid INTERVIEW_TIMEOUT: %w", err)
+		}
+		c.InterviewTimeout = timeout
+	}
+
+	setString(&c.OutputDir, "OUTPUT_DIR")
+
+	if err := applyStageEnv(&c.Stages.Extraction, "EXTRACTION"); err != nil {
+		return err
+	}
+	return applyStageEnv(&c.Stages.Validation, "VALIDATION")
+}
+
+// applyStageEnv читает переменные вида EXTRACTION_MODEL, EXTRACTION_TEMPERATURE и т.д.
+func applyStageEnv(stage *StageConfig, prefix string) error {
+	setString(&stage.Model, prefix+"_MODEL")
+	setString(&stage.BaseURL, prefix+"_BASE_URL")
+
+	if value := os.Getenv(prefix + "_TEMPERATURE"); value != "" {
+		temperature, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s_TEMPERATURE: %w", prefix, err)
+		}
+		stage.Temperature = temperature
+	}
+
+	if value := os.Getenv(prefix + "_MAX_TOKENS"); value != "" {
+		maxTokens, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s_MAX_TOKENS: %w", prefix, err)
+		}
+		stage.MaxTokens = maxTokens
+	}
+
+	return nil
+}
+
+// validate проверяет значения, которые нельзя исправить подстановкой по умолчанию
+func (c *Config) validate() error {
+	switch c.Extraction.Mode {
+	case ExtractionWhole, ExtractionBlocks:
+	default:
+		return fmt.Errorf("invalid extraction mode %q: expected %s or %s", c.Extraction.Mode, ExtractionWhole, ExtractionBlocks)
+	}
+	return nil
+}
+
+// ResolveStages подставляет в этапы модель и endpoint провайдера,
+// чтобы в метаданные попадали фактически используемые значения.
+// Load вызывает его сам; нужен для конфигурации, собранной в коде.
+func (c *Config) ResolveStages() {
+	for _, stage := range []*StageConfig{&c.Stages.Extraction, &c.Stages.Validation} {
+		if stage.Model == "" {
+			stage.Model = c.Provider.Model
+		}
+		if stage.Model == "" {
+			stage.Model = api.DefaultModel(c.Provider.Type)
+		}
+		if stage.BaseURL == "" {
+			stage.BaseURL = c.Provider.BaseURL
+		}
+		if stage.BaseURL == "" {
+			stage.BaseURL = api.DefaultBaseURL(c.Provider.Type)
+		}
+	}
+}
+
+func setString(target *string, env string) {
+	if value, ok := os.LookupEnv(env); ok && value != "" {
+		*target = value
+	}
+}
+
+func setInt(target *int, env string) error {
+	value := os.Getenv(env)
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", env, err)
+	}
+	*target = parsed
+	return nil
+}
@@ -0,0 +1,161 @@
+package fakellm
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"profile-extractor/internal/schema"
+)
+
+const (
+	ValuesRules  = "rules"
+	ValuesRandom = "random"
+)
+
+var randomWords = []string{
+	"семья", "поддержка", "университет", "путешествия", "музыка", "спорт",
+	"ответственность", "честность", "программирование", "книги", "друзья", "развитие",
+}
+
+// generateProfile строит профиль, соответствующий словарю. Поля вида
+// "location.city" становятся вложенными объектами, как того ждет валидатор.
+func generateProfile(schemaFields map[string]schema.SchemaField, mode string, rnd *rand.Rand) map[string]interface{} {
+	profile := make(map[string]interface{})
+
+	keys := make([]string, 0, len(schemaFields))
+	for key := range schemaFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		parts := strings.Split(key, ".")
+		node := profile
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[part] = child
+			}
+			node = child
+		}
+
+		leaf := parts[len(parts)-1]
+		if _, exists := node[leaf]; exists {
+			// Поле уже используется как родитель для вложенных полей
+			continue
+		}
+		node[leaf] = generateValue(schemaFields[key], leaf, mode, rnd)
+	}
+
+	return profile
+}
+
+func generateValue(field schema.SchemaField, name, mode string, rnd *rand.Rand) interface{} {
+	// Значение из enum проходит валидатор; для массива это один допустимый элемент
+	if len(field.Enum) > 0 {
+		value := field.Enum[0]
+		if mode == ValuesRandom {
+			value = field.Enum[rnd.Intn(len(field.Enum))]
+		}
+		if field.Type == "array" {
+			return []interface{}{value}
+		}
+		return value
+	}
+
+	// Часть необязательных полей остается пустой, как в реальных интервью
+	if mode == ValuesRandom && !field.Required && rnd.Float64() < 0.2 {
+		return nil
+	}
+
+	return typedValue(field, name, mode, rnd)
+}
+
+// typedValue строит непустое значение типа поля. Элементы array<T> и ключи
+// {key: T} заполняются по своим типам, как того ждет валидатор.
+func typedValue(field schema.SchemaField, name, mode string, rnd *rand.Rand) interface{} {
+	if field.Items != nil && field.Items.Type != "string" {
+		count := 2
+		if mode == ValuesRandom {
+			count = rnd.Intn(3) + 1
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			items[i] = typedValue(*field.Items, name, mode, rnd)
+		}
+		return items
+	}
+	if len(field.Nested) > 0 {
+		object := make(map[string]interface{}, len(field.Nested))
+		for _, key := range field.NestedKeys() {
+			object[key] = generateValue(field.Nested[key], key, mode, rnd)
+		}
+		return object
+	}
+
+	if mode != ValuesRandom {
+		return clampNumber(ruleValue(field, name), field)
+	}
+
+	switch field.Type {
+	case "int":
+		return clampNumber(rnd.Intn(60)+1, field)
+	case "float":
+		return clampNumber(float64(rnd.Intn(1000))/10, field)
+	case "bool":
+		return rnd.Intn(2) == 1
+	case "array":
+		items := make([]interface{}, rnd.Intn(3)+1)
+		for i := range items {
+			items[i] = randomWords[rnd.Intn(len(randomWords))]
+		}
+		return items
+	case "object":
+		return map[string]interface{}{name: randomWords[rnd.Intn(len(randomWords))]}
+	default:
+		return randomWords[rnd.Intn(len(randomWords))]
+	}
+}
+
+// ruleValue выводит значение из имени поля, чтобы результат был предсказуем
+func ruleValue(field schema.SchemaField, name string) interface{} {
+	switch field.Type {
+	case "int":
+		return len(name)
+	case "float":
+		return float64(len(name)) / 2
+	case "bool":
+		return true
+	case "array":
+		return []interface{}{fmt.Sprintf("%s 1", name), fmt.Sprintf("%s 2", name)}
+	case "object":
+		return map[string]interface{}{"value": name}
+	default:
+		return fmt.Sprintf("пример: %s", name)
+	}
+}
+
+// clampNumber приводит число к диапазону min/max поля
+func clampNumber(value interface{}, field schema.SchemaField) interface{} {
+	clamp := func(v float64) float64 {
+		if field.Min != nil && v < *field.Min {
+			v = *field.Min
+		}
+		if field.Max != nil && v > *field.Max {
+			v = *field.Max
+		}
+		return v
+	}
+
+	switch v := value.(type) {
+	case int:
+		return int(math.Ceil(clamp(float64(v))))
+	case float64:
+		return clamp(v)
+	}
+	return value
+}
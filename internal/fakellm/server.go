@@ -0,0 +1,293 @@
+// Package fakellm реализует OpenAI-совместимый chat completions сервер,
+// который отвечает профилями по словарю без обращения к настоящей модели.
+// Подходит для локальной разработки, e2e и chaos тестов через httptest.
+package fakellm
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"profile-extractor/internal/api"
+	"profile-extractor/internal/schema"
+)
+
+// Faults задает вероятности (0..1) и параметры искусственных сбоев
+type Faults struct {
+	// Latency — задержка перед каждым ответом
+	Latency time.Duration
+	// LatencyJitter — случайная добавка к задержке, от 0 до LatencyJitter
+	LatencyJitter time.Duration
+	RateLimit     float64
+	ServerError   float64
+	// Malformed — ответ с markdown, висячими запятыми и текстом вокруг JSON
+	Malformed float64
+	// Truncate — ответ обрывается с finish_reason "length"; остаток
+	// отдается на запрос продолжения
+	Truncate float64
+	// RetryAfter — значение заголовка Retry-After для ответов 429
+	RetryAfter time.Duration
+}
+
+// Options — настройки фейкового сервера
+type Options struct {
+	// Values — rules (значения выводятся из имен полей) или random
+	Values string
+	// Seed делает случайные значения и сбои воспроизводимыми
+	Seed   int64
+	Faults Faults
+}
+
+// Server — http.Handler с контрактом POST /chat/completions
+type Server struct {
+	schemaFields map[string]schema.SchemaField
+	opts         Options
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+	// pending — остатки оборванных ответов по отданному началу
+	pending map[string]string
+}
+
+// New создает сервер, отвечающий профилями по словарю
+func New(schemaFields map[string]schema.SchemaField, opts Options) *Server {
+	if opts.Values == "" {
+		opts.Values = ValuesRules
+	}
+	return &Server{
+		schemaFields: schemaFields,
+		opts:         opts,
+		rnd:          rand.New(rand.NewSource(opts.Seed)),
+		pending:      make(map[string]string),
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/chat/completions") {
+		writeError(w, http.StatusNotFound, "unknown endpoint: "+r.URL.Path, "invalid_request_error")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed", "invalid_request_error")
+		return
+	}
+
+	var req api.OpenAIRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error(), "invalid_request_error")
+		return
+	}
+	if len(req.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "messages must not be empty", "invalid_request_error")
+		return
+	}
+
+	if delay := s.latency(); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case s.roll(s.opts.Faults.RateLimit):
+		if s.opts.Faults.RetryAfter > 0 {
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(s.opts.Faults.RetryAfter.Seconds())))
+		}
+		writeError(w, http.StatusTooManyRequests, "Rate limit exceeded", "rate_limit_error")
+		return
+	case s.roll(s.opts.Faults.ServerError):
+		writeError(w, http.StatusInternalServerError, "Internal server error", "server_error")
+		return
+	}
+
+	content, finishReason := s.complete(req)
+
+	prompt := 0
+	for _, msg := range req.Messages {
+		prompt += api.EstimateTokens(msg.Content)
+	}
+	completion := api.EstimateTokens(content)
+	usage := api.Usage{
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+	}
+
+	if req.Stream {
+		s.stream(w, r, req, content, finishReason, usage)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, api.OpenAIResponse{
+		Model: req.Model,
+		Choices: []api.Choice{{
+			Message:      api.Message{Role: "assistant", Content: content},
+			FinishReason: finishReason,
+		}},
+		Usage: usage,
+	})
+}
+
+// streamChunkRunes — размер чанка потокового ответа
+const streamChunkRunes = 16
+
+// stream отдает ответ событиями SSE в формате chat.completion.chunk
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, req api.OpenAIRequest, content, finishReason string, usage api.Usage) {
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(chunk api.OpenAIStreamChunk) bool {
+		data, _ := json.Marshal(chunk)
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return r.Context().Err() == nil
+	}
+
+	runes := []rune(content)
+	for start := 0; start < len(runes); start += streamChunkRunes {
+		end := start + streamChunkRunes
+		if end > len(runes) {
+			end = len(runes)
+		}
+		chunk := api.OpenAIStreamChunk{
+			Model:   req.Model,
+			Choices: []api.StreamChoice{{Delta: api.Message{Content: string(runes[start:end])}}},
+		}
+		if !send(chunk) {
+			return
+		}
+	}
+
+	if !send(api.OpenAIStreamChunk{
+		Model:   req.Model,
+		Choices: []api.StreamChoice{{FinishReason: finishReason}},
+	}) {
+		return
+	}
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		if !send(api.OpenAIStreamChunk{Model: req.Model, Choices: []api.StreamChoice{}, Usage: &usage}) {
+			return
+		}
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// complete возвращает текст ответа и finish_reason
+func (s *Server) complete(req api.OpenAIRequest) (string, string) {
+	// Запрос продолжения: предыдущее сообщение — наш оборванный ответ
+	if len(req.Messages) >= 2 {
+		previous := req.Messages[len(req.Messages)-2]
+		if previous.Role == "assistant" {
+			s.mu.Lock()
+			rest, ok := s.pending[previous.Content]
+			delete(s.pending, previous.Content)
+			s.mu.Unlock()
+			if ok {
+				return rest, "stop"
+			}
+		}
+	}
+
+	profile := generateProfile(s.schemaFields, s.opts.Values, s.requestRand(req))
+	data, _ := json.Marshal(profile)
+	content := string(data)
+
+	if s.roll(s.opts.Faults.Malformed) {
+		content = s.malform(content)
+	}
+
+	if s.roll(s.opts.Faults.Truncate) {
+		cut := len(content) / 2
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		head, rest := content[:cut], content[cut:]
+		s.mu.Lock()
+		s.pending[head] = rest
+		s.mu.Unlock()
+		return head, "length"
+	}
+
+	return content, "stop"
+}
+
+// requestRand дает генератор, зависящий от seed и текста запроса, чтобы
+// одинаковый промпт получал одинаковый профиль
+func (s *Server) requestRand(req api.OpenAIRequest) *rand.Rand {
+	hash := sha256.New()
+	for _, msg := range req.Messages {
+		hash.Write([]byte(msg.Role))
+		hash.Write([]byte(msg.Content))
+	}
+	seed := int64(binary.BigEndian.Uint64(hash.Sum(nil))) ^ s.opts.Seed
+	return rand.New(rand.NewSource(seed))
+}
+
+// malform портит JSON так, как это делают настоящие модели
+func (s *Server) malform(content string) string {
+	s.mu.Lock()
+	variant := s.rnd.Intn(3)
+	s.mu.Unlock()
+
+	switch variant {
+	case 0:
+		return "Вот профиль:\n```json\n" + content + "\n```\nНадеюсь, помог!"
+	case 1:
+		// Висячая запятая перед закрывающей скобкой
+		return strings.TrimSuffix(content, "}") + ",}"
+	default:
+		return content + "\n\nПримечание: часть полей заполнена предположительно."
+	}
+}
+
+func (s *Server) roll(probability float64) bool {
+	if probability <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rnd.Float64() < probability
+}
+
+func (s *Server) latency() time.Duration {
+	delay := s.opts.Faults.Latency
+	if jitter := s.opts.Faults.LatencyJitter; jitter > 0 {
+		s.mu.Lock()
+		delay += time.Duration(s.rnd.Int63n(int64(jitter)))
+		s.mu.Unlock()
+	}
+	return delay
+}
+
+func writeError(w http.ResponseWriter, status int, message, errorType string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": api.APIError{Message: message, Type: errorType, Code: status},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("fakellm: error writing response: %v", err)
+	}
+}
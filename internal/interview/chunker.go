@@ -0,0 +1,100 @@
+package interview
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitByTokens делит интервью на части, текст которых (ExtractContextualAnswers)
+// укладывается в maxTokens. Границы проходят только между блоками и парами
+// вопрос-ответ; пара, которая одна больше бюджета, становится отдельной частью.
+// estimate оценивает число токенов в тексте. maxTokens <= 0 отключает деление.
+func (i *Interview) SplitByTokens(maxTokens int, estimate func(string) int) []*Interview {
+	if maxTokens <= 0 || estimate(i.ExtractContextualAnswers()) <= maxTokens {
+		return []*Interview{i}
+	}
+
+	var chunks []*Interview
+	current := i.emptyChunk()
+	used := 0
+
+	flush := func() {
+		if len(current.Blocks) > 0 {
+			chunks = append(chunks, current)
+		}
+		current = i.emptyChunk()
+		used = 0
+	}
+
+	for _, block := range i.Blocks {
+		header := estimate(fmt.Sprintf("=== %s ===\n", formatBlockName(block.BlockName)))
+		// Блок открывается в текущей части только вместе с первым ответом
+		opened := false
+
+		for _, qa := range block.QuestionsAndAnswers {
+			if strings.TrimSpace(qa.Answer) == "" {
+				continue
+			}
+
+			cost := estimate(fmt.Sprintf("На вопрос: %s\nОтвет: %s\n\n", qa.Question, qa.Answer))
+			if !opened {
+				cost += header
+			}
+
+			if used > 0 && used+cost > maxTokens {
+				flush()
+				if opened {
+					// Продолжение блока в новой части снова получает заголовок
+					cost += header
+					opened = false
+				}
+			}
+
+			if !opened {
+				current.Blocks = append(current.Blocks, Block{
+					BlockID:   block.BlockID,
+					BlockName: block.BlockName,
+				})
+				opened = true
+			}
+
+			last := &current.Blocks[len(current.Blocks)-1]
+			last.QuestionsAndAnswers = append(last.QuestionsAndAnswers, qa)
+			used += cost
+		}
+	}
+	flush()
+
+	return chunks
+}
+
+func (i *Interview) emptyChunk() *Interview {
+	return &Interview{
+		InterviewID: i.InterviewID,
+		Timestamp:   i.Timestamp,
+	}
+}
+
+// SplitByBlock возвращает по интервью на каждый блок с ответами
+func (i *Interview) SplitByBlock() []*Interview {
+	var blocks []*Interview
+
+	for _, block := range i.Blocks {
+		hasAnswers := false
+		for _, qa := range block.QuestionsAndAnswers {
+			if strings.TrimSpace(qa.Answer) != "" {
+				hasAnswers = true
+				break
+			}
+		}
+		if !hasAnswers {
+			continue
+		}
+
+		part := i.emptyChunk()
+		part.Blocks = []Block{block}
+		blocks = append(blocks, part)
+	}
+
+	return blocks
+}
@@ -0,0 +1,123 @@
+package interview
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// estimateX считает токеном каждую букву x в ответах и каждый заголовок
+// блока, чтобы стоимость пар вопрос-ответ была очевидна из теста
+func estimateX(text string) int {
+	return strings.Count(text, "x") + strings.Count(text, "=== ")
+}
+
+// testInterview строит интервью, в котором ответ — строка из n букв x
+func testInterview(blocks ...[]int) *Interview {
+	i := &Interview{InterviewID: "test", Timestamp: "2024-01-01"}
+	for id, answers := range blocks {
+		block := Block{BlockID: id + 1, BlockName: fmt.Sprintf("b%d", id+1)}
+		for _, n := range answers {
+			block.QuestionsAndAnswers = append(block.QuestionsAndAnswers, QuestionAndAnswer{
+				Question: "вопрос",
+				Answer:   strings.Repeat("x", n),
+			})
+		}
+		i.Blocks = append(i.Blocks, block)
+	}
+	return i
+}
+
+// layout описывает части как "b1[4 5] b2[3]"
+func layout(chunks []*Interview) []string {
+	var result []string
+	for _, chunk := range chunks {
+		var blocks []string
+		for _, block := range chunk.Blocks {
+			sizes := make([]string, len(block.QuestionsAndAnswers))
+			for i, qa := range block.QuestionsAndAnswers {
+				sizes[i] = fmt.Sprint(len(qa.Answer))
+			}
+			blocks = append(blocks, block.BlockName+"["+strings.Join(sizes, " ")+"]")
+		}
+		result = append(result, strings.Join(blocks, " "))
+	}
+	return result
+}
+
+func TestSplitByTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks [][]int
+		budget int
+		want   []string
+	}{
+		{
+			// Заголовок b1 (1) + 4 + 5 = 10: ровно бюджет, часть не делится
+			name:   "block exactly at budget",
+			blocks: [][]int{{4, 5}, {3}},
+			budget: 10,
+			want:   []string{"b1[4 5]", "b2[3]"},
+		},
+		{
+			// 1 + 4 + 6 = 11: блок делится, продолжение снова получает заголовок
+			name:   "block one token over budget",
+			blocks: [][]int{{4, 6}},
+			budget: 10,
+			want:   []string{"b1[4]", "b1[6]"},
+		},
+		{
+			name:   "small blocks share a chunk",
+			blocks: [][]int{{2}, {3}, {2}, {8}},
+			budget: 10,
+			want:   []string{"b1[2] b2[3] b3[2]", "b4[8]"},
+		},
+		{
+			// Ответ больше бюджета становится отдельной частью
+			name:   "oversize answer",
+			blocks: [][]int{{2, 25, 2}},
+			budget: 10,
+			want:   []string{"b1[2]", "b1[25]", "b1[2]"},
+		},
+		{
+			name:   "empty answers are skipped",
+			blocks: [][]int{{4, 0, 5}, {0}, {6}},
+			budget: 10,
+			want:   []string{"b1[4 5]", "b3[6]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := testInterview(tt.blocks...)
+			chunks := i.SplitByTokens(tt.budget, estimateX)
+			if got := layout(chunks); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunks = %q, want %q", got, tt.want)
+			}
+			for n, chunk := range chunks {
+				if chunk.InterviewID != i.InterviewID || chunk.Timestamp != i.Timestamp {
+					t.Errorf("chunk %d lost interview metadata: %+v", n, chunk)
+				}
+				if cost := estimateX(chunk.ExtractContextualAnswers()); cost > tt.budget && len(chunk.Blocks[0].QuestionsAndAnswers) > 1 {
+					t.Errorf("chunk %d costs %d, over budget %d", n, cost, tt.budget)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitByTokensKeepsSmallInterview(t *testing.T) {
+	i := testInterview([]int{4, 5}, []int{3})
+
+	// 1 + 4 + 5 + 1 + 3 = 14
+	for _, budget := range []int{0, -1, 14, 100} {
+		chunks := i.SplitByTokens(budget, estimateX)
+		if len(chunks) != 1 || chunks[0] != i {
+			t.Errorf("budget %d: got %d chunks, want the interview itself", budget, len(chunks))
+		}
+	}
+	if chunks := i.SplitByTokens(13, estimateX); len(chunks) != 2 {
+		t.Errorf("budget 13: got %d chunks, want 2", len(chunks))
+	}
+}
@@ -0,0 +1,148 @@
+package interview
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TextBuilder строит из интервью текст, который получает модель
+type TextBuilder interface {
+	// Name — имя способа; записывается в _metadata.processing_info.extraction_method
+	Name() string
+	Build(i *Interview) string
+}
+
+// DefaultTextBuilder — способ построения текста по умолчанию
+const DefaultTextBuilder = "contextual"
+
+type textBuilderFunc struct {
+	name  string
+	build func(*Interview) string
+}
+
+func (b textBuilderFunc) Name() string              { return b.name }
+func (b textBuilderFunc) Build(i *Interview) string { return b.build(i) }
+
+// NewTextBuilder создает TextBuilder из функции
+func NewTextBuilder(name string, build func(*Interview) string) TextBuilder {
+	return textBuilderFunc{name: name, build: build}
+}
+
+// WithAnswerFilter строит текст способом base только по парам вопрос-ответ,
+// для которых keep возвращает true. Блоки без оставшихся ответов пропускаются.
+func WithAnswerFilter(name string, base TextBuilder, keep func(QuestionAndAnswer) bool) TextBuilder {
+	return NewTextBuilder(name, func(i *Interview) string {
+		filtered := i.emptyChunk()
+		for _, block := range i.Blocks {
+			var kept []QuestionAndAnswer
+			for _, qa := range block.QuestionsAndAnswers {
+				if keep(qa) {
+					kept = append(kept, qa)
+				}
+			}
+			if len(kept) == 0 {
+				continue
+			}
+			block.QuestionsAndAnswers = kept
+			filtered.Blocks = append(filtered.Blocks, block)
+		}
+		return base.Build(filtered)
+	})
+}
+
+var (
+	buildersMu sync.RWMutex
+	builders   = make(map[string]TextBuilder)
+)
+
+// RegisterTextBuilder делает способ доступным по имени из CLI и config.yaml.
+// Вызывается при инициализации; повторное имя — ошибка программы, поэтому panic.
+func RegisterTextBuilder(b TextBuilder) {
+	buildersMu.Lock()
+	defer buildersMu.Unlock()
+
+	name := b.Name()
+	if name == "" {
+		panic("interview: text builder with empty name")
+	}
+	if _, exists := builders[name]; exists {
+		panic(fmt.Sprintf("interview: text builder %q registered twice", name))
+	}
+	builders[name] = b
+}
+
+// LookupTextBuilder возвращает зарегистрированный способ по имени
+func LookupTextBuilder(name string) (TextBuilder, error) {
+	buildersMu.RLock()
+	defer buildersMu.RUnlock()
+
+	b, ok := builders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown extraction method %q: expected one of %s",
+			name, strings.Join(textBuilderNames(), ", "))
+	}
+	return b, nil
+}
+
+// TextBuilderNames возвращает имена зарегистрированных способов по алфавиту
+func TextBuilderNames() []string {
+	buildersMu.RLock()
+	defer buildersMu.RUnlock()
+	return textBuilderNames()
+}
+
+func textBuilderNames() []string {
+	names := make([]string, 0, len(builders))
+	for name := range builders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	// Все ответы подряд
+	RegisterTextBuilder(NewTextBuilder("all", (*Interview).ExtractAllAnswers))
+	// Ответы с контекстом вопросов (рекомендуется)
+	RegisterTextBuilder(NewTextBuilder("contextual", (*Interview).ExtractContextualAnswers))
+	// Ответы, сгруппированные по блокам
+	RegisterTextBuilder(NewTextBuilder("by-block", (*Interview).extractBlockText))
+	// Пары вопрос-ответ в Markdown
+	RegisterTextBuilder(NewTextBuilder("markdown", (*Interview).ExtractMarkdown))
+}
+
+// extractBlockText объединяет ответы каждого блока под заголовком блока
+func (i *Interview) extractBlockText() string {
+	answers := i.ExtractAnswersByBlock()
+
+	var parts []string
+	for _, block := range i.Blocks {
+		if text := answers[block.BlockName]; strings.TrimSpace(text) != "" {
+			parts = append(parts, fmt.Sprintf("=== %s ===\n%s", formatBlockName(block.BlockName), text))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// ExtractMarkdown выводит блоки заголовками, а пары вопрос-ответ — Markdown разметкой
+func (i *Interview) ExtractMarkdown() string {
+	var b strings.Builder
+
+	for _, block := range i.Blocks {
+		headerWritten := false
+		for _, qa := range block.QuestionsAndAnswers {
+			if strings.TrimSpace(qa.Answer) == "" {
+				continue
+			}
+			if !headerWritten {
+				fmt.Fprintf(&b, "## %s\n\n", formatBlockName(block.BlockName))
+				headerWritten = true
+			}
+			fmt.Fprintf(&b, "**%s**\n\n%s\n\n", strings.TrimSpace(qa.Question), strings.TrimSpace(qa.Answer))
+		}
+	}
+
+	return strings.TrimSpace(b.String())
+}
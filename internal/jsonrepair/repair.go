@@ -0,0 +1,373 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Repair находит в ответе модели внешний JSON объект и исправляет типичные
+// синтаксические ошибки: markdown блоки, текст вокруг JSON, висячие запятые,
+// одинарные кавычки, ключи без кавычек, Python литералы, комментарии и
+// оборванный вывод. Возвращает валидный JSON и список выполненных исправлений.
+func Repair(raw string) (string, []string, error) {
+	var repairs []string
+
+	text := raw
+	if strings.Contains(text, "```") {
+		text = strings.ReplaceAll(text, "```json", "")
+		text = strings.ReplaceAll(text, "```", "")
+		repairs = append(repairs, "removed markdown code fence")
+	}
+	text = strings.TrimSpace(text)
+
+	// Быстрый путь — ответ уже валиден
+	if json.Valid([]byte(text)) && strings.HasPrefix(text, "{") {
+		return text, repairs, nil
+	}
+
+	start := strings.Index(text, "{")
+	if start < 0 {
+		return "", repairs, fmt.Errorf("no JSON object found in response")
+	}
+	if strings.TrimSpace(text[:start]) != "" {
+		repairs = append(repairs, "removed text before JSON object")
+	}
+
+	s := &scanner{input: text[start:]}
+	s.scan()
+	repairs = append(repairs, s.repairs...)
+
+	if rest := strings.TrimSpace(s.input[s.end:]); rest != "" {
+		if strings.HasPrefix(rest, "{") {
+			repairs = append(repairs, "ignored additional JSON object after the first one")
+		} else {
+			repairs = append(repairs, "removed text after JSON object")
+		}
+	}
+
+	result := s.out.String()
+	if s.truncated {
+		closed, ok := closeTruncated(result)
+		if !ok {
+			return "", repairs, fmt.Errorf("could not repair truncated JSON")
+		}
+		result = closed
+		repairs = append(repairs, "closed truncated JSON")
+	}
+
+	if !json.Valid([]byte(result)) {
+		return "", repairs, fmt.Errorf("could not repair JSON")
+	}
+
+	return result, repairs, nil
+}
+
+// scanner переписывает JSON-подобный текст в валидный JSON за один проход
+type scanner struct {
+	input     string
+	pos       int
+	end       int
+	out       strings.Builder
+	stack     []byte
+	truncated bool
+	repairs   []string
+	seen      map[string]bool
+}
+
+func (s *scanner) note(repair string) {
+	if s.seen == nil {
+		s.seen = make(map[string]bool)
+	}
+	if !s.seen[repair] {
+		s.seen[repair] = true
+		s.repairs = append(s.repairs, repair)
+	}
+}
+
+func (s *scanner) scan() {
+	for s.pos < len(s.input) {
+		c := s.input[s.pos]
+
+		switch {
+		case c == '"' || c == '\'':
+			if !s.scanString(c) {
+				s.truncated = true
+				s.end = len(s.input)
+				return
+			}
+			continue
+		case c == '{' || c == '[':
+			s.stack = append(s.stack, c)
+			s.out.WriteByte(c)
+		case c == '}' || c == ']':
+			if s.trimTrailingComma() {
+				s.note("removed trailing comma")
+			}
+			if len(s.stack) > 0 {
+				s.stack = s.stack[:len(s.stack)-1]
+			}
+			s.out.WriteByte(c)
+			if len(s.stack) == 0 {
+				s.pos++
+				s.end = s.pos
+				return
+			}
+		case c == '/' && s.pos+1 < len(s.input) && (s.input[s.pos+1] == '/' || s.input[s.pos+1] == '*'):
+			s.skipComment()
+			s.note("removed comment")
+			continue
+		case c == '-' || isDigit(c):
+			s.scanNumber()
+			continue
+		case isIdentStart(s.peekRune()):
+			s.scanIdent()
+			continue
+		default:
+			s.out.WriteByte(c)
+		}
+		s.pos++
+	}
+
+	s.truncated = len(s.stack) > 0
+	s.end = len(s.input)
+}
+
+// scanString копирует строку, приводя одинарные кавычки к двойным.
+// Возвращает false, если строка оборвана.
+func (s *scanner) scanString(quote byte) bool {
+	if quote == '\'' {
+		s.note("replaced single quotes with double quotes")
+	}
+
+	s.out.WriteByte('"')
+	s.pos++
+	for s.pos < len(s.input) {
+		c := s.input[s.pos]
+		switch {
+		case c == '\\' && s.pos+1 < len(s.input):
+			next := s.input[s.pos+1]
+			if quote == '\'' && next == '\'' {
+				s.out.WriteByte('\'')
+			} else {
+				s.out.WriteByte(c)
+				s.out.WriteByte(next)
+			}
+			s.pos += 2
+			continue
+		case c == quote:
+			s.out.WriteByte('"')
+			s.pos++
+			return true
+		case c == '"':
+			// Двойная кавычка внутри строки в одинарных кавычках
+			s.out.WriteString(`\"`)
+		case c == '\n':
+			s.out.WriteString(`\n`)
+			s.note("escaped newline in string")
+		case c == '\\':
+			// Одиночный обратный слеш в конце ввода
+			s.pos++
+			continue
+		default:
+			s.out.WriteByte(c)
+		}
+		s.pos++
+	}
+
+	s.out.WriteByte('"')
+	return false
+}
+
+func (s *scanner) skipComment() {
+	if s.input[s.pos+1] == '/' {
+		for s.pos < len(s.input) && s.input[s.pos] != '\n' {
+			s.pos++
+		}
+		return
+	}
+
+	s.pos += 2
+	for s.pos+1 < len(s.input) && !(s.input[s.pos] == '*' && s.input[s.pos+1] == '/') {
+		s.pos++
+	}
+	s.pos += 2
+	if s.pos > len(s.input) {
+		s.pos = len(s.input)
+	}
+}
+
+// scanNumber копирует число целиком по грамматике JSON: знак, дробная
+// часть и экспонента, чтобы 1e5 не распадалось на число и голое слово
+func (s *scanner) scanNumber() {
+	startPos := s.pos
+	if s.input[s.pos] == '-' {
+		s.pos++
+	}
+	s.skipDigits()
+	if s.pos < len(s.input) && s.input[s.pos] == '.' {
+		s.pos++
+		s.skipDigits()
+	}
+	if s.pos < len(s.input) && (s.input[s.pos] == 'e' || s.input[s.pos] == 'E') {
+		s.pos++
+		if s.pos < len(s.input) && (s.input[s.pos] == '+' || s.input[s.pos] == '-') {
+			s.pos++
+		}
+		s.skipDigits()
+	}
+	s.out.WriteString(s.input[startPos:s.pos])
+}
+
+func (s *scanner) skipDigits() {
+	for s.pos < len(s.input) && isDigit(s.input[s.pos]) {
+		s.pos++
+	}
+}
+
+func (s *scanner) peekRune() rune {
+	r, _ := utf8.DecodeRuneInString(s.input[s.pos:])
+	return r
+}
+
+// scanIdent обрабатывает слова вне строк: литералы и ключи без кавычек
+func (s *scanner) scanIdent() {
+	startPos := s.pos
+	for s.pos < len(s.input) {
+		r, size := utf8.DecodeRuneInString(s.input[s.pos:])
+		if !isIdentPart(r) {
+			break
+		}
+		s.pos += size
+	}
+	word := s.input[startPos:s.pos]
+
+	switch word {
+	case "true", "false", "null":
+		s.out.WriteString(word)
+		return
+	case "True", "False":
+		s.out.WriteString(strings.ToLower(word))
+		s.note("converted Python literals")
+		return
+	case "None", "undefined", "NaN":
+		s.out.WriteString("null")
+		s.note("converted non-JSON literals to null")
+		return
+	}
+
+	if s.nextNonSpace() == ':' {
+		s.out.WriteString(`"` + word + `"`)
+		s.note("quoted unquoted keys")
+		return
+	}
+
+	// Голое слово в позиции значения — сохраняем как строку
+	s.out.WriteString(`"` + word + `"`)
+	s.note("quoted bare words")
+}
+
+func (s *scanner) nextNonSpace() byte {
+	for i := s.pos; i < len(s.input); i++ {
+		if !unicode.IsSpace(rune(s.input[i])) {
+			return s.input[i]
+		}
+	}
+	return 0
+}
+
+// trimTrailingComma удаляет запятую перед закрывающей скобкой
+func (s *scanner) trimTrailingComma() bool {
+	current := s.out.String()
+	trimmed := strings.TrimRightFunc(current, unicode.IsSpace)
+	if !strings.HasSuffix(trimmed, ",") {
+		return false
+	}
+	s.out.Reset()
+	s.out.WriteString(trimmed[:len(trimmed)-1])
+	return true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentStart допускает любые буквы: ключи без кавычек бывают кириллическими
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r) || r == '-'
+}
+
+// closeTruncated дописывает закрывающие скобки для оборванного JSON.
+// Пробует несколько вариантов и возвращает первый валидный; в крайнем
+// случае отбрасывает незавершенные элементы с конца.
+func closeTruncated(partial string) (string, bool) {
+	base := strings.TrimRightFunc(partial, unicode.IsSpace)
+	base = strings.TrimSuffix(base, ",")
+
+	for _, suffix := range []string{"", ": null", "null"} {
+		if candidate := closeOpen(base + suffix); json.Valid([]byte(candidate)) {
+			return candidate, true
+		}
+	}
+
+	for i := len(base) - 1; i > 0; i-- {
+		if base[i] != ',' && base[i] != '{' && base[i] != '[' {
+			continue
+		}
+		candidate := closeOpen(strings.TrimSuffix(base[:i+1], ","))
+		if json.Valid([]byte(candidate)) {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+// closeOpen закрывает незакрытую строку и все открытые скобки
+func closeOpen(text string) string {
+	var stack []byte
+	inString := false
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			stack = append(stack, c)
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(text)
+	if inString {
+		builder.WriteByte('"')
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == '{' {
+			builder.WriteByte('}')
+		} else {
+			builder.WriteByte(']')
+		}
+	}
+
+	return builder.String()
+}
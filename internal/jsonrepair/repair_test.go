@@ -0,0 +1,121 @@
+package jsonrepair_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"profile-extractor/internal/jsonrepair"
+)
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		want   string
+		repair string
+	}{
+		{
+			name:   "trailing comma in object",
+			raw:    `{"a": 1, "b": [1, 2,],}`,
+			want:   `{"a": 1, "b": [1, 2]}`,
+			repair: "removed trailing comma",
+		},
+		{
+			name:   "exponent",
+			raw:    `{"a": 1e5,}`,
+			want:   `{"a": 1e5}`,
+			repair: "removed trailing comma",
+		},
+		{
+			name:   "signed fraction with exponent",
+			raw:    `{"a": -1.5E-3, "b": 2e+2,}`,
+			want:   `{"a": -1.5E-3, "b": 2e+2}`,
+			repair: "removed trailing comma",
+		},
+		{
+			name:   "unquoted cyrillic keys",
+			raw:    `{имя: "x", опыт_лет: 5}`,
+			want:   `{"имя": "x", "опыт_лет": 5}`,
+			repair: "quoted unquoted keys",
+		},
+		{
+			name:   "single quotes",
+			raw:    `{'name': 'O\'Brien', 'city': 'Москва'}`,
+			want:   `{"name": "O'Brien", "city": "Москва"}`,
+			repair: "replaced single quotes with double quotes",
+		},
+		{
+			name:   "double quote inside single quotes",
+			raw:    `{'quote': 'say "hi"'}`,
+			want:   `{"quote": "say \"hi\""}`,
+			repair: "replaced single quotes with double quotes",
+		},
+		{
+			name:   "python literals",
+			raw:    `{"a": True, "b": None}`,
+			want:   `{"a": true, "b": null}`,
+			repair: "converted Python literals",
+		},
+		{
+			name:   "truncated",
+			raw:    `{"a": [1, 2`,
+			want:   `{"a": [1, 2]}`,
+			repair: "closed truncated JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, repairs, err := jsonrepair.Repair(tt.raw)
+			if err != nil {
+				t.Fatalf("Repair(%q): %v", tt.raw, err)
+			}
+			assertJSONEqual(t, got, tt.want)
+			if !contains(repairs, tt.repair) {
+				t.Errorf("repairs = %q, want %q", repairs, tt.repair)
+			}
+		})
+	}
+}
+
+func TestRepairKeepsValidJSON(t *testing.T) {
+	raw := `{"a": 1e5, "b": -1.5E-3, "имя": "x"}`
+	got, repairs, err := jsonrepair.Repair(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != raw || len(repairs) != 0 {
+		t.Errorf("Repair(%q) = %q, %q; want input unchanged", raw, got, repairs)
+	}
+}
+
+func TestRepairNoObject(t *testing.T) {
+	if _, _, err := jsonrepair.Repair("нет данных"); err == nil {
+		t.Error("expected error for response without JSON object")
+	}
+}
+
+func assertJSONEqual(t *testing.T, got, want string) {
+	t.Helper()
+
+	var gotValue, wantValue any
+	if err := json.Unmarshal([]byte(got), &gotValue); err != nil {
+		t.Fatalf("result %q is not valid JSON: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
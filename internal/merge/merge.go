@@ -0,0 +1,208 @@
+// Package merge объединяет частичные профили, извлеченные из частей интервью
+package merge
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Conflict — поле, для которого части интервью дали разные значения
+type Conflict struct {
+	Field  string        `json:"field"`
+	Values []interface{} `json:"values"`
+	Chosen interface{}   `json:"chosen"`
+}
+
+// Profiles детерминированно объединяет частичные профили в порядке частей:
+//   - объекты объединяются рекурсивно;
+//   - массивы склеиваются без повторов, порядок — по первому появлению;
+//   - из разных скалярных значений выбирается самое частое, при равенстве —
+//     самая длинная строка, затем самое раннее значение;
+//   - null и отсутствующие поля не перекрывают найденные значения.
+func Profiles(partials []map[string]interface{}) (map[string]interface{}, []Conflict) {
+	values := make([]interface{}, len(partials))
+	for i, partial := range partials {
+		values[i] = partial
+	}
+
+	var conflicts []Conflict
+	merged, _ := mergeValues("", values, &conflicts)
+
+	result, ok := merged.(map[string]interface{})
+	if !ok {
+		result = make(map[string]interface{})
+	}
+	return result, conflicts
+}
+
+func mergeValues(path string, values []interface{}, conflicts *[]Conflict) (interface{}, bool) {
+	var present []interface{}
+	hasObject, hasArray := false, false
+	for _, value := range values {
+		switch value.(type) {
+		case nil:
+			continue
+		case map[string]interface{}:
+			hasObject = true
+		case []interface{}:
+			hasArray = true
+		}
+		present = append(present, value)
+	}
+
+	switch {
+	case len(present) == 0:
+		return nil, false
+	case hasObject:
+		return mergeObjects(path, present, conflicts), true
+	case hasArray:
+		return mergeArrays(present), true
+	default:
+		return mergeScalars(path, present, conflicts), true
+	}
+}
+
+func mergeObjects(path string, values []interface{}, conflicts *[]Conflict) map[string]interface{} {
+	var objects []map[string]interface{}
+	var dropped []interface{}
+	for _, value := range values {
+		if object, ok := value.(map[string]interface{}); ok {
+			objects = append(objects, object)
+		} else {
+			dropped = append(dropped, value)
+		}
+	}
+
+	merged := make(map[string]interface{})
+	if len(dropped) > 0 {
+		*conflicts = append(*conflicts, Conflict{Field: path, Values: values, Chosen: "object"})
+	}
+
+	keys := make(map[string]bool)
+	for _, object := range objects {
+		for key := range object {
+			keys[key] = true
+		}
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	for _, key := range sortedKeys {
+		childValues := make([]interface{}, 0, len(objects))
+		for _, object := range objects {
+			childValues = append(childValues, object[key])
+		}
+		if value, ok := mergeValues(joinPath(path, key), childValues, conflicts); ok {
+			merged[key] = value
+		} else {
+			// Поле есть в схеме, но ни одна часть его не заполнила
+			merged[key] = nil
+		}
+	}
+
+	return merged
+}
+
+// mergeArrays склеивает массивы; скаляр рядом с массивом становится его элементом
+func mergeArrays(values []interface{}) []interface{} {
+	merged := []interface{}{}
+	seen := make(map[string]bool)
+
+	add := func(item interface{}) {
+		key := canonical(item)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		merged = append(merged, item)
+	}
+
+	for _, value := range values {
+		if items, ok := value.([]interface{}); ok {
+			for _, item := range items {
+				add(item)
+			}
+			continue
+		}
+		add(value)
+	}
+
+	return merged
+}
+
+func mergeScalars(path string, values []interface{}, conflicts *[]Conflict) interface{} {
+	type candidate struct {
+		value interface{}
+		count int
+		first int
+	}
+
+	var candidates []*candidate
+	byKey := make(map[string]*candidate)
+	for i, value := range values {
+		key := canonical(value)
+		if c, ok := byKey[key]; ok {
+			c.count++
+			continue
+		}
+		c := &candidate{value: value, count: 1, first: i}
+		byKey[key] = c
+		candidates = append(candidates, c)
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0].value
+	}
+
+	// Значения в порядке первого появления для отчета о конфликте
+	distinct := make([]interface{}, len(candidates))
+	for i, c := range candidates {
+		distinct[i] = c.value
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		if la, lb := stringLength(a.value), stringLength(b.value); la != lb {
+			return la > lb
+		}
+		return a.first < b.first
+	})
+
+	*conflicts = append(*conflicts, Conflict{Field: path, Values: distinct, Chosen: candidates[0].value})
+
+	return candidates[0].value
+}
+
+// canonical дает ключ сравнения значений; строки сравниваются без учета регистра и пробелов по краям
+func canonical(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return "s:" + strings.ToLower(strings.TrimSpace(s))
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+func stringLength(value interface{}) int {
+	if s, ok := value.(string); ok {
+		return len([]rune(s))
+	}
+	return 0
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,126 @@
+package merge
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProfilesScalarConflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []interface{}
+		want   interface{}
+	}{
+		{"most frequent wins", []interface{}{"Казань", "Москва", "москва "}, "Москва"},
+		{"tie goes to longest string", []interface{}{"Москва", "Санкт-Петербург"}, "Санкт-Петербург"},
+		{"tie of equal length goes to earliest", []interface{}{"ИП", "АО"}, "ИП"},
+		{"numbers", []interface{}{30.0, 31.0, 31.0}, 31.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partials := make([]map[string]interface{}, len(tt.values))
+			for i, value := range tt.values {
+				partials[i] = map[string]interface{}{"city": value}
+			}
+
+			merged, conflicts := Profiles(partials)
+			if got := merged["city"]; got != tt.want {
+				t.Errorf("city = %v, want %v", got, tt.want)
+			}
+			if len(conflicts) != 1 || conflicts[0].Field != "city" || conflicts[0].Chosen != tt.want {
+				t.Errorf("conflicts = %+v, want one conflict for city choosing %v", conflicts, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfilesSameValueIsNotConflict(t *testing.T) {
+	merged, conflicts := Profiles([]map[string]interface{}{
+		{"name": "Анна"},
+		{"name": " анна"},
+	})
+	if merged["name"] != "Анна" {
+		t.Errorf("name = %v, want first spelling", merged["name"])
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none", conflicts)
+	}
+}
+
+func TestProfilesArrays(t *testing.T) {
+	merged, conflicts := Profiles([]map[string]interface{}{
+		{"skills": []interface{}{"Go", "SQL"}},
+		{"skills": []interface{}{"sql", "Kubernetes", "Go"}},
+		{"skills": "Docker"},
+		{"skills": []interface{}{map[string]interface{}{"name": "Go"}, map[string]interface{}{"name": "Go"}}},
+	})
+
+	want := []interface{}{"Go", "SQL", "Kubernetes", "Docker", map[string]interface{}{"name": "Go"}}
+	if !reflect.DeepEqual(merged["skills"], want) {
+		t.Errorf("skills = %v, want %v", merged["skills"], want)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none", conflicts)
+	}
+}
+
+func TestProfilesNestedObjects(t *testing.T) {
+	merged, conflicts := Profiles([]map[string]interface{}{
+		{"location": map[string]interface{}{"current": map[string]interface{}{"city": "Казань", "country": nil}}},
+		{"location": map[string]interface{}{"current": map[string]interface{}{"country": "Россия"}, "birth": "Уфа"}},
+		{"location": map[string]interface{}{"current": map[string]interface{}{"city": "Москва"}}},
+	})
+
+	want := map[string]interface{}{
+		"location": map[string]interface{}{
+			"birth": "Уфа",
+			"current": map[string]interface{}{
+				"city":    "Казань",
+				"country": "Россия",
+			},
+		},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("merged = %v, want %v", merged, want)
+	}
+	if len(conflicts) != 1 || conflicts[0].Field != "location.current.city" {
+		t.Errorf("conflicts = %+v, want one conflict for location.current.city", conflicts)
+	}
+}
+
+func TestProfilesNullDoesNotOverrideValue(t *testing.T) {
+	merged, conflicts := Profiles([]map[string]interface{}{
+		{"age": nil, "city": nil},
+		{"age": 30.0},
+		{"age": nil, "hobby": nil},
+	})
+
+	if merged["age"] != 30.0 {
+		t.Errorf("age = %v, want 30", merged["age"])
+	}
+	// Поле, которое не заполнила ни одна часть, остается null
+	for _, key := range []string{"city", "hobby"} {
+		if value, ok := merged[key]; !ok || value != nil {
+			t.Errorf("%s = %v (present %v), want null", key, value, ok)
+		}
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none", conflicts)
+	}
+}
+
+func TestProfilesObjectWinsOverScalar(t *testing.T) {
+	merged, conflicts := Profiles([]map[string]interface{}{
+		{"family": "полная семья"},
+		{"family": map[string]interface{}{"structure": "полная"}},
+	})
+
+	want := map[string]interface{}{"structure": "полная"}
+	if !reflect.DeepEqual(merged["family"], want) {
+		t.Errorf("family = %v, want %v", merged["family"], want)
+	}
+	if len(conflicts) != 1 || conflicts[0].Field != "family" || conflicts[0].Chosen != "object" {
+		t.Errorf("conflicts = %+v, want one conflict for family choosing object", conflicts)
+	}
+}
@@ -1,7 +1,9 @@
 package prompts
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"profile-extractor/internal/schema"
@@ -18,21 +20,16 @@ func GenerateExtractionPrompt(schemaFields map[string]schema.SchemaField, userTe
 1. ФИКСИРОВАННЫЕ ПОЛЯ: Извлеки только те поля, которые есть в схеме выше
 2. ТИПЫ ДАННЫХ: Строго соблюдай указанные типы (string, int, array, object)
 3. ТОЧЕЧНАЯ НОТАЦИЯ: Поля вида "location.city" создавай как вложенные объекты {"location": {"city": "значение"}}
-4. МАССИВЫ: Поля типа array создавай как массивы объектов
+4. МАССИВЫ: Поля типа array<T> заполняй элементами ровно типа T: array<string> — массив строк, array<{company: string, from: int}> — массив объектов только с этими ключами (неизвестное значение ключа — null). Поля типа array без типа элементов создавай как массивы объектов
 5. ОБЯЗАТЕЛЬНЫЕ ПОЛЯ: Если данных нет - ставь null, НЕ ПРИДУМЫВАЙ
 6. ТЕГИ: После заполнения основных полей создай section "tags" для дополнительной информации
 
 ПРИМЕРЫ ПРАВИЛЬНЫХ СТРУКТУР:
-- education: array → "education": [{"university": "МГУ", "degree": "бакалавр", "year": 2020}]
-- skills: array → "skills": [{"name": "Go", "level": "advanced"}, {"name": "Python", "level": "intermediate"}]
-- location.city: string → "location": {"city": "Москва"}
-- social.telegram: string → "social": {"telegram": "@username"}
-- tags: object → "tags": {"hobby": "фотография", "personality": "коммуникабельный"}
-
+%s
 ВАЖНО:
 - Возвращай ТОЛЬКО валидный JSON без markdown блоков и трех обратных кавычек
 - Никаких дополнительных комментариев или объяснений
-- Для полей типа array создавай объекты с осмысленными ключами
+- Для полей типа array без типа элементов создавай объекты с осмысленными ключами
 - Теги используй для информации, которая не поместилась в стандартные поля
 - Не дублируй информацию между основными полями и тегами
 
@@ -42,18 +39,21 @@ func GenerateExtractionPrompt(schemaFields map[string]schema.SchemaField, userTe
 ОТВЕТ (чистый JSON без оформления, без markdown блоков и трех обратных кавычек):`
 
 	schemaDescription := generateSchemaDescription(schemaFields)
-	return fmt.Sprintf(prompt, schemaDescription, userText)
+	return fmt.Sprintf(prompt, schemaDescription, generateExamples(schemaFields), userText)
 }
 
-func GenerateValidationPrompt(profileJSON string) string {
+func GenerateValidationPrompt(schemaFields map[string]schema.SchemaField, profileJSON string) string {
 	return fmt.Sprintf(`Ты эксперт по валидации данных. Проверь профиль и исправь найденные проблемы.
 
+СХЕМА ДАННЫХ:
+%s
 ПРОВЕРКИ:
 1. ДУБЛИРОВАНИЕ: Удали из "tags" информацию, которая дублируется с основными полями
-2. ТИПЫ ДАННЫХ: Убедись, что все поля соответствуют нужным типам
-3. ЛОГИКА: Проверь на противоречия (например, age: 25 и education.year: 2030)
+2. ТИПЫ ДАННЫХ: Убедись, что все поля соответствуют типам из схемы
+3. ЛОГИКА: Проверь на противоречия (например, age: 25 и начало работы в 2030 году)
 4. СТРУКТУРА: Убедись, что JSON валиден и правильно структурирован
 5. КОНСИСТЕНТНОСТЬ: Проверь логическую связность данных
+6. МАССИВЫ: Элементы полей array<T> должны быть ровно типа T, у объектов — только ключи из схемы
 
 ПРАВИЛА ИСПРАВЛЕНИЯ:
 - Приоритет у основных полей, теги - вторичны
@@ -63,28 +63,238 @@ func GenerateValidationPrompt(profileJSON string) string {
 - Если поле должно быть числом, но пришла строка - попробуй преобразовать
 
 ПРИМЕРЫ ПРОБЛЕМ И РЕШЕНИЙ:
-- Дубль: skills: [{"name": "Go"}] + tags: {"programming": "Go"} → удали тег
-- Тип: age: "25" → age: 25
+%s- Тип: age: "25" → age: 25
 - Противоречие: age: 20, experience_years: 10 → исправь experience_years: 2
 
 ПРОФИЛЬ ДЛЯ ПРОВЕРКИ:
 %s
 
-ОТВЕТ (чистый исправленный JSON без markdown оформления, без markdown блоков и трех обратных кавычек):`, profileJSON)
+ОТВЕТ (чистый исправленный JSON без markdown оформления, без markdown блоков и трех обратных кавычек):`, generateSchemaDescription(schemaFields), duplicateExample(schemaFields), profileJSON)
+}
+
+// GenerateChunkExtractionPrompt строит промпт для одной из частей длинного интервью.
+// Частичные профили затем объединяются, поэтому модель не должна заполнять поля,
+// о которых в этой части ничего нет.
+func GenerateChunkExtractionPrompt(schemaFields map[string]schema.SchemaField, userText string, part, total int) string {
+	note := fmt.Sprintf(`ЧАСТЬ ИНТЕРВЬЮ %d ИЗ %d:
+Ниже только часть интервью. Заполняй лишь поля, для которых в этой части есть данные, остальные ставь null.
+Профили всех частей будут объединены автоматически.
+
+`, part, total)
+	return note + GenerateExtractionPrompt(schemaFields, userText)
+}
+
+// GenerateRepairPrompt просит модель исправить конкретные ошибки валидатора
+func GenerateRepairPrompt(schemaFields map[string]schema.SchemaField, profileJSON string, validationErrors []string) string {
+	var errorsList strings.Builder
+	for i, message := range validationErrors {
+		errorsList.WriteString(fmt.Sprintf("%d. %s\n", i+1, message))
+	}
+
+	return fmt.Sprintf(`Ты эксперт по валидации данных. Профиль не прошел проверку по схеме. Исправь ТОЛЬКО перечисленные ошибки.
+
+СХЕМА ДАННЫХ:
+%s
+
+НАЙДЕННЫЕ ОШИБКИ:
+%s
+ПРАВИЛА ИСПРАВЛЕНИЯ:
+- Поля вида "location.city" должны быть вложенными объектами {"location": {"city": "значение"}}
+- Приводи значения к типу из схемы без потери смысла (например, "25" → 25)
+- Элементы полей array<T> приводи к типу T: лишние ключи объектов перенеси в ключи из схемы или удали
+- Если значение невозможно привести к нужному типу - ставь null, НЕ ПРИДУМЫВАЙ
+- Не меняй и не удаляй поля, в которых нет ошибок
+
+ТЕКУЩИЙ ПРОФИЛЬ:
+%s
+
+ОТВЕТ (чистый исправленный JSON без markdown оформления, без markdown блоков и трех обратных кавычек):`, generateSchemaDescription(schemaFields), errorsList.String(), profileJSON)
 }
 
 func generateSchemaDescription(schemaFields map[string]schema.SchemaField) string {
 	var builder strings.Builder
 
-	for _, field := range schemaFields {
-		if field.IsArray {
-			builder.WriteString(fmt.Sprintf("- %s: array\n", field.Name))
-		} else if field.IsObject {
-			builder.WriteString(fmt.Sprintf("- %s: object\n", field.Name))
-		} else {
-			builder.WriteString(fmt.Sprintf("- %s: %s\n", field.Name, field.Type))
+	for _, key := range sortedKeys(schemaFields) {
+		field := schemaFields[key]
+		builder.WriteString(fmt.Sprintf("- %s: %s", field.Name, field.TypeString()))
+		builder.WriteString(describeField(field))
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// sortedKeys возвращает имена полей по алфавиту. Одинаковый словарь должен
+// давать одинаковый промпт, иначе ключ кэша ответов не повторится.
+func sortedKeys(schemaFields map[string]schema.SchemaField) []string {
+	keys := make([]string, 0, len(schemaFields))
+	for key := range schemaFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// generateExamples строит примеры структур по словарю: для полей с типом
+// элементов array<T> и объектных типов {key: T} — значение ровно этой формы,
+// для точечной нотации — первое вложенное поле простого типа.
+func generateExamples(schemaFields map[string]schema.SchemaField) string {
+	var builder strings.Builder
+
+	dotted := false
+	for _, key := range sortedKeys(schemaFields) {
+		field := schemaFields[key]
+		typed := field.Items != nil || len(field.Nested) > 0
+		if !typed {
+			if dotted || !strings.Contains(field.Name, ".") || field.IsArray || field.IsObject {
+				continue
+			}
+			dotted = true
 		}
+		builder.WriteString(fmt.Sprintf("- %s: %s → %s\n", field.Name, field.TypeString(), exampleEntry(field)))
 	}
+	builder.WriteString(`- tags: object → "tags": {"hobby": "фотография", "personality": "коммуникабельный"}` + "\n")
 
 	return builder.String()
 }
+
+// duplicateExample показывает дубль тега на первом поле вида array<T> с
+// элементами простого типа. Без таких полей пример описывается словами.
+func duplicateExample(schemaFields map[string]schema.SchemaField) string {
+	for _, key := range sortedKeys(schemaFields) {
+		field := schemaFields[key]
+		if field.Items == nil || field.Items.IsArray || field.Items.IsObject {
+			continue
+		}
+		path := strings.Split(field.Name, ".")
+		return fmt.Sprintf("- Дубль: %s + \"tags\": {%q: %s} → удали тег\n",
+			exampleEntry(field), path[len(path)-1], exampleValue(*field.Items))
+	}
+	return "- Дубль: значение основного поля повторено в \"tags\" → удали тег\n"
+}
+
+// exampleEntry записывает пример значения поля с точечной нотацией как
+// вложенные объекты: "location": {"city": "текст"}
+func exampleEntry(field schema.SchemaField) string {
+	path := strings.Split(field.Name, ".")
+	entry := exampleValue(field)
+	for i := len(path) - 1; i > 0; i-- {
+		entry = fmt.Sprintf("{%q: %s}", path[i], entry)
+	}
+	return fmt.Sprintf("%q: %s", path[0], entry)
+}
+
+// exampleValue — пример значения ровно типа поля: из examples или enum
+// словаря, иначе заглушка по типу. У массива examples и enum относятся к элементам.
+func exampleValue(field schema.SchemaField) string {
+	if field.IsArray {
+		item := schema.SchemaField{Type: "string"}
+		if field.Items != nil {
+			item = *field.Items
+		} else if len(field.Examples) == 0 && len(field.Enum) == 0 {
+			return "[]"
+		}
+		item.Examples, item.Enum = field.Examples, field.Enum
+		return "[" + exampleValue(item) + "]"
+	}
+
+	switch {
+	case len(field.Examples) > 0:
+		return formatJSON(field.Examples[0])
+	case len(field.Enum) > 0:
+		return formatJSON(field.Enum[0])
+	case len(field.Nested) > 0:
+		parts := make([]string, 0, len(field.Nested))
+		for _, key := range field.NestedKeys() {
+			parts = append(parts, fmt.Sprintf("%q: %s", key, exampleValue(field.Nested[key])))
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	}
+
+	switch field.Type {
+	case "int":
+		return "1"
+	case "float":
+		return "1.5"
+	case "bool":
+		return "true"
+	case "object":
+		return "{}"
+	default:
+		return `"текст"`
+	}
+}
+
+// describeField описывает атрибуты расширенной формы поля. Для краткой формы
+// строка пустая, и промпт не меняется.
+func describeField(field schema.SchemaField) string {
+	var details []string
+	if field.Description != "" {
+		details = append(details, field.Description)
+	}
+	if len(field.Enum) > 0 {
+		details = append(details, "допустимые значения: "+formatValues(field.Enum))
+	}
+	if field.Min != nil || field.Max != nil {
+		details = append(details, describeRange(field))
+	}
+	if field.Pattern != "" {
+		details = append(details, "формат (регулярное выражение): "+field.Pattern)
+	}
+	if len(field.Examples) > 0 {
+		details = append(details, "примеры: "+formatValues(field.Examples))
+	}
+
+	var text string
+	if field.Required {
+		text = " (обязательное: найди в тексте)"
+	}
+	if len(details) > 0 {
+		text += " — " + strings.Join(details, "; ")
+	}
+	return text
+}
+
+// describeRange описывает min/max: для строки это длина, для массива — число элементов
+func describeRange(field schema.SchemaField) string {
+	var what string
+	switch field.Type {
+	case "string":
+		what = "длина в символах"
+	case "array":
+		what = "число элементов"
+	default:
+		what = "значение"
+	}
+
+	switch {
+	case field.Min != nil && field.Max != nil:
+		return fmt.Sprintf("%s от %v до %v", what, *field.Min, *field.Max)
+	case field.Min != nil:
+		return fmt.Sprintf("%s не меньше %v", what, *field.Min)
+	default:
+		return fmt.Sprintf("%s не больше %v", what, *field.Max)
+	}
+}
+
+// formatValues перечисляет значения через запятую; не строки записываются в JSON
+func formatValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			parts[i] = s
+			continue
+		}
+		parts[i] = formatJSON(value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatJSON записывает значение из словаря в JSON
+func formatJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,254 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GenerateGo строит Go код с типизированной структурой профиля: поля с
+// точечной нотацией становятся вложенными структурами (family.childhood.structure →
+// Profile.Family.Childhood.Structure) с JSON тегами, плюс функция Decode.
+// source записывается в заголовок сгенерированного файла.
+func GenerateGo(schemaFields map[string]SchemaField, packageName, source string) ([]byte, error) {
+	g := &goGenerator{typeNames: make(map[string]string)}
+	if err := g.structType("Profile", "", buildTree(schemaFields), true); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by profile-extractor schema codegen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "package %s\n\n", packageName)
+	b.WriteString("import \"encoding/json\"\n\n")
+	b.Write(g.types.Bytes())
+	b.WriteString(`// Decode разбирает профиль в формате profile_<id>.json
+func Decode(data []byte) (*Profile, error) {
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+`)
+
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated code: %w", err)
+	}
+	return code, nil
+}
+
+type goGenerator struct {
+	types bytes.Buffer
+	// typeNames — путь раздела по имени типа, чтобы найти совпадения имен
+	typeNames map[string]string
+	// pending — структуры объектных типов полей, которые выводятся после текущей
+	pending []pendingType
+}
+
+// pendingType — структура для типа {key: T, ...} поля или элемента массива
+type pendingType struct {
+	name  string
+	about string
+	field SchemaField
+}
+
+func (g *goGenerator) reserve(name, path string) error {
+	if other, exists := g.typeNames[name]; exists {
+		return fmt.Errorf("sections %q and %q both map to Go type %s", other, path, name)
+	}
+	g.typeNames[name] = path
+	return nil
+}
+
+// structType выводит структуру объекта node и, вслед за ней, структуры вложенных объектов
+func (g *goGenerator) structType(name, path string, node *schemaNode, root bool) error {
+	if err := g.reserve(name, path); err != nil {
+		return err
+	}
+
+	children := make([]string, 0, len(node.children))
+	for key := range node.children {
+		children = append(children, key)
+	}
+	sort.Strings(children)
+
+	if root {
+		fmt.Fprintf(&g.types, "// %s — профиль по словарю полей\n", name)
+	} else {
+		fmt.Fprintf(&g.types, "// %s — раздел %s\n", name, path)
+	}
+	fmt.Fprintf(&g.types, "type %s struct {\n", name)
+
+	fieldNames := make(map[string]string)
+	var nested []string
+	for _, key := range children {
+		fieldName := goName(key)
+		if other, exists := fieldNames[fieldName]; exists {
+			return fmt.Errorf("fields %q and %q of section %q both map to Go field %s", other, key, path, fieldName)
+		}
+		fieldNames[fieldName] = key
+
+		child := node.children[key]
+		if child.field == nil {
+			// omitempty не действует на структуры, раздел всегда записывается объектом
+			fmt.Fprintf(&g.types, "\t%s %s `json:\"%s\"`\n", fieldName, nestedTypeName(name, key, root), key)
+			nested = append(nested, key)
+			continue
+		}
+		if description := child.field.Description; description != "" {
+			fmt.Fprintf(&g.types, "\t// %s\n", strings.Join(strings.Fields(description), " "))
+		}
+		fieldPath := key
+		if !root {
+			fieldPath = path + "." + key
+		}
+		goType, err := g.goType(*child.field, nestedTypeName(name, key, root), fieldPath)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&g.types, "\t%s %s `json:\"%s,omitempty\"`\n", fieldName, nullable(goType), key)
+	}
+	if root {
+		if other, exists := fieldNames["Metadata"]; exists {
+			return fmt.Errorf("field %q maps to Go field Metadata reserved for _metadata", other)
+		}
+		g.types.WriteString("\n\t// Metadata — _metadata: исходное интервью и сведения об обработке\n")
+		g.types.WriteString("\tMetadata map[string]interface{} `json:\"_metadata,omitempty\"`\n")
+	}
+	g.types.WriteString("}\n\n")
+
+	if err := g.flushPending(); err != nil {
+		return err
+	}
+
+	for _, key := range nested {
+		childPath := key
+		if !root {
+			childPath = path + "." + key
+		}
+		if err := g.structType(nestedTypeName(name, key, root), childPath, node.children[key], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nestedTypeName — имя типа вложенного раздела: разделы профиля называются
+// по ключу (Family), вложенные — с именем родителя (FamilyChildhood)
+func nestedTypeName(parent, key string, root bool) string {
+	if root {
+		return goName(key)
+	}
+	return parent + goName(key)
+}
+
+// goType возвращает Go тип значения поля, без указателя. Для объектного типа {key: T, ...}
+// (в том числе элементов array<T>) откладывает вывод структуры с именем typeName.
+func (g *goGenerator) goType(field SchemaField, typeName, path string) (string, error) {
+	switch field.Type {
+	case "int":
+		return "int", nil
+	case "float":
+		return "float64", nil
+	case "bool":
+		return "bool", nil
+	case "array":
+		if field.Items == nil {
+			return "[]interface{}", nil
+		}
+		itemType, err := g.goType(*field.Items, typeName+"Item", path+"[]")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + itemType, nil
+	case "object":
+		if len(field.Nested) == 0 {
+			return "map[string]interface{}", nil
+		}
+		if err := g.reserve(typeName, path); err != nil {
+			return "", err
+		}
+		about := "значение " + path
+		if strings.HasSuffix(path, "[]") {
+			about = "элемент массива " + strings.TrimSuffix(path, "[]")
+		}
+		g.pending = append(g.pending, pendingType{name: typeName, about: about, field: field})
+		return typeName, nil
+	default:
+		return "string", nil
+	}
+}
+
+// nullable возвращает тип, который отличает null от нулевого значения. Любое
+// поле профиля может быть null, а с omitempty 0, "" и false при записи терялись бы.
+// Срезы и map и так бывают nil; элементы массивов null не бывают.
+func nullable(goType string) string {
+	if strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") {
+		return goType
+	}
+	return "*" + goType
+}
+
+// flushPending выводит отложенные структуры объектных типов
+func (g *goGenerator) flushPending() error {
+	for len(g.pending) > 0 {
+		t := g.pending[0]
+		g.pending = g.pending[1:]
+
+		var fields bytes.Buffer
+		fieldNames := make(map[string]string)
+		for _, key := range t.field.NestedKeys() {
+			fieldName := goName(key)
+			if other, exists := fieldNames[fieldName]; exists {
+				return fmt.Errorf("keys %q and %q of %s both map to Go field %s", other, key, t.name, fieldName)
+			}
+			fieldNames[fieldName] = key
+
+			goType, err := g.goType(t.field.Nested[key], t.name+fieldName, g.typeNames[t.name]+"."+key)
+			if err != nil {
+				return err
+			}
+			// Отсутствующий ключ равносилен null
+			fmt.Fprintf(&fields, "\t%s %s `json:\"%s,omitempty\"`\n", fieldName, nullable(goType), key)
+		}
+
+		fmt.Fprintf(&g.types, "// %s — %s\ntype %s struct {\n", t.name, t.about, t.name)
+		g.types.Write(fields.Bytes())
+		g.types.WriteString("}\n\n")
+	}
+	return nil
+}
+
+// goInitialisms пишутся в именах Go заглавными целиком
+var goInitialisms = map[string]bool{
+	"id": true, "url": true, "api": true, "json": true, "http": true, "html": true, "ip": true, "uuid": true,
+}
+
+// goName превращает ключ словаря в экспортируемое имя Go: experience_years → ExperienceYears
+func goName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, word := range words {
+		if goInitialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		// Имя Go не может начинаться с цифры
+		name = "F" + name
+	}
+	return name
+}
@@ -0,0 +1,143 @@
+package schema
+
+import (
+	"sort"
+	"strings"
+)
+
+// ToJSONSchema строит JSON Schema профиля из полей с точечной нотацией.
+// Поля вида "location.current.city" становятся вложенными объектами,
+// все листовые поля допускают null, чтобы модель не придумывала данные.
+func ToJSONSchema(schemaFields map[string]SchemaField) map[string]interface{} {
+	return buildTree(schemaFields).toJSONSchema()
+}
+
+// buildTree раскладывает поля с точечной нотацией в дерево объектов
+func buildTree(schemaFields map[string]SchemaField) *schemaNode {
+	root := newObjectNode()
+
+	// Сортировка делает дерево детерминированным при одинаковом словаре
+	keys := make([]string, 0, len(schemaFields))
+	for key := range schemaFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		parts := strings.Split(key, ".")
+		node := root
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node.children[part]
+			if !ok || child.field != nil {
+				child = newObjectNode()
+				node.children[part] = child
+			}
+			node = child
+		}
+
+		leaf := parts[len(parts)-1]
+		if _, exists := node.children[leaf]; exists {
+			// Поле уже используется как родитель для вложенных полей
+			continue
+		}
+		field := schemaFields[key]
+		node.children[leaf] = &schemaNode{field: &field}
+	}
+
+	return root
+}
+
+type schemaNode struct {
+	field    *SchemaField
+	children map[string]*schemaNode
+}
+
+func newObjectNode() *schemaNode {
+	return &schemaNode{children: make(map[string]*schemaNode)}
+}
+
+func (n *schemaNode) toJSONSchema() map[string]interface{} {
+	if n.field != nil {
+		return fieldJSONSchema(*n.field)
+	}
+
+	properties := make(map[string]interface{}, len(n.children))
+	required := make([]string, 0, len(n.children))
+	for name, child := range n.children {
+		properties[name] = child.toJSONSchema()
+		required = append(required, name)
+	}
+	sort.Strings(required)
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func fieldJSONSchema(field SchemaField) map[string]interface{} {
+	result := typeJSONSchema(field)
+	if field.Description != "" {
+		result["description"] = field.Description
+	}
+	if len(field.Enum) > 0 {
+		if items, ok := result["items"].(map[string]interface{}); ok {
+			items["enum"] = field.Enum
+		} else {
+			// null остается допустимым, как и для остальных листовых полей
+			result["enum"] = append(append([]interface{}{}, field.Enum...), nil)
+		}
+	}
+	return result
+}
+
+// typeJSONSchema описывает тип листового поля; null допустим, чтобы модель не придумывала данные
+func typeJSONSchema(field SchemaField) map[string]interface{} {
+	result := valueJSONSchema(field)
+	result["type"] = []string{result["type"].(string), "null"}
+	return result
+}
+
+// valueJSONSchema описывает непустое значение: элемент массива array<T>
+// или поле со снятым null. Ключи объекта {key: T} обязательны, но допускают null.
+func valueJSONSchema(field SchemaField) map[string]interface{} {
+	switch field.Type {
+	case "int":
+		return map[string]interface{}{"type": "integer"}
+	case "float":
+		return map[string]interface{}{"type": "number"}
+	case "bool":
+		return map[string]interface{}{"type": "boolean"}
+	case "array":
+		items := map[string]interface{}{}
+		if field.Items != nil {
+			items = valueJSONSchema(*field.Items)
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": items,
+		}
+	case "object":
+		if len(field.Nested) == 0 {
+			return map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": true,
+			}
+		}
+		keys := field.NestedKeys()
+		properties := make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			properties[key] = typeJSONSchema(field.Nested[key])
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             keys,
+			"additionalProperties": false,
+		}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
@@ -2,6 +2,9 @@ package schema
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v2"
@@ -12,9 +15,52 @@ type SchemaField struct {
 	Type     string
 	IsArray  bool
 	IsObject bool
-	Nested   map[string]SchemaField
+	// Nested — ключи объектного типа {key: T, ...}
+	Nested map[string]SchemaField
+	// Items — тип элементов массива array<T>; nil для array без типа элементов
+	Items *SchemaField
+
+	// Атрибуты расширенной формы поля; в краткой форме "key: type" пусты
+	Description string
+	// Required — поле должно быть заполнено (не отсутствовать и не быть null)
+	Required bool
+	// Enum — допустимые значения; для массива — допустимые элементы
+	Enum     []interface{}
+	Examples []interface{}
+	// Min и Max ограничивают число, длину строки в символах или число элементов массива
+	Min *float64
+	Max *float64
+	// Pattern — регулярное выражение для строки, PatternRegexp — оно же,
+	// скомпилированное при загрузке словаря
+	Pattern       string
+	PatternRegexp *regexp.Regexp
+}
+
+// fieldDefinition — расширенная форма поля в dictionary.yaml:
+//
+//	personality.type:
+//	  type: string
+//	  description: тип личности по MBTI
+//	  enum: [INTJ, ENFP]
+type fieldDefinition struct {
+	Type        string        `yaml:"type"`
+	Description string        `yaml:"description"`
+	Required    bool          `yaml:"required"`
+	Enum        []interface{} `yaml:"enum"`
+	Examples    []interface{} `yaml:"examples"`
+	Min         *float64      `yaml:"min"`
+	Max         *float64      `yaml:"max"`
+	Pattern     string        `yaml:"pattern"`
+}
+
+// fieldTypes — типы, которые понимают промпты, валидатор и JSON Schema.
+// Массив может указать тип элементов: array<string>, array<{company: string}>.
+var fieldTypes = map[string]bool{
+	"string": true, "int": true, "float": true, "bool": true, "array": true, "object": true,
 }
 
+// ParseYAMLSchema разбирает словарь. Поле задается кратко ("key: type") или
+// расширенной формой с типом, описанием, ограничениями и примерами.
 func ParseYAMLSchema(yamlContent []byte) (map[string]SchemaField, error) {
 	schema := make(map[string]interface{})
 	err := yaml.Unmarshal(yamlContent, &schema)
@@ -25,14 +71,9 @@ func ParseYAMLSchema(yamlContent []byte) (map[string]SchemaField, error) {
 	result := make(map[string]SchemaField)
 
 	for key, value := range schema {
-		field := SchemaField{
-			Name: key,
-			Type: parseType(value),
-		}
-
-		// Обработка точечной нотации
-		if strings.Contains(key, ".") {
-			field = parseNestedField(key, value)
+		field, err := parseField(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", key, err)
 		}
 
 		// Определение массивов и объектов
@@ -46,9 +87,53 @@ func ParseYAMLSchema(yamlContent []byte) (map[string]SchemaField, error) {
 		result[key] = field
 	}
 
+	if err := checkPrefixes(result); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
+// checkPrefixes запрещает поле, которое одновременно лист и родитель полей
+// с точечной нотацией (a и a.b): в профиле и JSON Schema a может быть либо
+// значением, либо объектом.
+func checkPrefixes(fields map[string]SchemaField) error {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		parts := strings.Split(key, ".")
+		for i := 1; i < len(parts); i++ {
+			prefix := strings.Join(parts[:i], ".")
+			if _, exists := fields[prefix]; exists {
+				return fmt.Errorf("field %s conflicts with field %s: a field cannot also be a parent of dotted fields", prefix, key)
+			}
+		}
+	}
+	return nil
+}
+
+// parseField разбирает краткую форму поля ("key: type") или расширенную
+func parseField(key string, value interface{}) (SchemaField, error) {
+	if definition, ok := value.(map[interface{}]interface{}); ok {
+		return parseDefinition(key, definition)
+	}
+
+	// Имя типа, массив с типом элементов или объект с набором ключей
+	field, err := parseTypeExpr(parseType(value))
+	if err != nil {
+		return SchemaField{}, err
+	}
+	if err := checkTypes(field); err != nil {
+		return SchemaField{}, err
+	}
+	field.Name = key
+	return field, nil
+}
+
 func parseType(value interface{}) string {
 	switch v := value.(type) {
 	case string:
@@ -68,25 +153,193 @@ func parseType(value interface{}) string {
 	}
 }
 
-func parseNestedField(dotKey string, value interface{}) SchemaField {
-	// Для точечной нотации создаем структуру вложенных полей
-	// location.city → {"location": {"city": "string"}}
+// parseDefinition разбирает расширенную форму поля. Неизвестные атрибуты —
+// ошибка, чтобы опечатка не отключала ограничение молча.
+func parseDefinition(key string, definition map[interface{}]interface{}) (SchemaField, error) {
+	data, err := yaml.Marshal(definition)
+	if err != nil {
+		return SchemaField{}, err
+	}
+	var def fieldDefinition
+	if err := yaml.UnmarshalStrict(data, &def); err != nil {
+		return SchemaField{}, err
+	}
+
+	if def.Type == "" {
+		def.Type = "string"
+	}
+	field, err := parseTypeExpr(def.Type)
+	if err != nil {
+		return SchemaField{}, err
+	}
+	if err := checkTypes(field); err != nil {
+		return SchemaField{}, err
+	}
+	def.Type = field.Type
+	if def.Min != nil && def.Max != nil && *def.Min > *def.Max {
+		return SchemaField{}, fmt.Errorf("min %v is greater than max %v", *def.Min, *def.Max)
+	}
+	if (def.Min != nil || def.Max != nil) && (def.Type == "bool" || def.Type == "object") {
+		return SchemaField{}, fmt.Errorf("min and max are not supported for type %s", def.Type)
+	}
+	if def.Pattern != "" {
+		if def.Type != "string" {
+			return SchemaField{}, fmt.Errorf("pattern is supported only for type string")
+		}
+		// Регулярное выражение компилируется один раз, а не для каждого профиля
+		field.PatternRegexp, err = regexp.Compile(def.Pattern)
+		if err != nil {
+			return SchemaField{}, fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	field.Name = key
+	field.Description = strings.TrimSpace(def.Description)
+	field.Required = def.Required
+	field.Enum = normalizeValues(def.Enum)
+	field.Examples = normalizeValues(def.Examples)
+	if err := checkValues("enum", field.Enum, field); err != nil {
+		return SchemaField{}, err
+	}
+	if err := checkValues("examples", field.Examples, field); err != nil {
+		return SchemaField{}, err
+	}
+	field.Min = def.Min
+	field.Max = def.Max
+	field.Pattern = def.Pattern
+	return field, nil
+}
+
+// normalizeValues приводит значения из YAML к виду, который дает разбор JSON:
+// числа — float64, объекты — map[string]interface{}
+func normalizeValues(values []interface{}) []interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	normalized := make([]interface{}, len(values))
+	for i, value := range values {
+		normalized[i] = normalizeValue(value)
+	}
+	return normalized
+}
+
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case []interface{}:
+		return normalizeValues(v)
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			object[fmt.Sprint(key)] = normalizeValue(item)
+		}
+		return object
+	default:
+		return v
+	}
+}
+
+// checkValues проверяет, что значения enum или examples подходят под тип поля,
+// у массива array<T> — под тип элементов. YAML без кавычек читает yes и no как
+// bool, а 1 как число: такой enum у строкового поля отверг бы любой ответ.
+func checkValues(attribute string, values []interface{}, field SchemaField) error {
+	valueField := field
+	if field.IsArray {
+		if field.Items == nil {
+			return nil
+		}
+		valueField = *field.Items
+	}
+
+	for _, value := range values {
+		if matchesType(value, valueField) {
+			continue
+		}
+		if valueField.Type == "string" {
+			return fmt.Errorf("%s value %v does not match type string, quote it in YAML", attribute, value)
+		}
+		return fmt.Errorf("%s value %v does not match type %s", attribute, value, valueField.TypeString())
+	}
+	return nil
+}
 
-	// Возвращаем только корневое поле, остальная логика в промптах
-	return SchemaField{
-		Name:     dotKey, // Сохраняем исходное имя для обработки в промптах
-		Type:     parseType(value),
-		IsObject: false,
-		Nested:   nil,
+// matchesType сообщает, подходит ли значение из словаря под тип поля.
+// Числа уже приведены к float64; null не подходит ни под один тип.
+func matchesType(value interface{}, field SchemaField) bool {
+	switch field.Type {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "int":
+		v, ok := value.(float64)
+		return ok && v == math.Trunc(v)
+	case "float":
+		_, ok := value.(float64)
+		return ok
+	case "bool":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		if field.Items != nil {
+			for _, item := range array {
+				if !matchesType(item, *field.Items) {
+					return false
+				}
+			}
+		}
+		return true
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if len(field.Nested) == 0 {
+			return true
+		}
+		for key, item := range object {
+			nested, known := field.Nested[key]
+			if !known || (item != nil && !matchesType(item, nested)) {
+				return false
+			}
+		}
+		return true
 	}
+	return false
 }
 
 func (s SchemaField) String() string {
-	if s.IsArray {
-		return fmt.Sprintf("%s: array", s.Name)
+	text := fmt.Sprintf("%s: %s", s.Name, s.TypeString())
+	if s.Required {
+		text += " (required)"
 	}
-	if s.IsObject && len(s.Nested) > 0 {
-		return fmt.Sprintf("%s: object with nested fields", s.Name)
+	if s.Description != "" {
+		text += " — " + s.Description
 	}
-	return fmt.Sprintf("%s: %s", s.Name, s.Type)
+	return text
+}
+
+// FilterSections оставляет поля из указанных разделов. Раздел "family" (или
+// "family.*") включает поле family и все поля вида family.<...>, "*" — все поля.
+func FilterSections(schemaFields map[string]SchemaField, sections []string) map[string]SchemaField {
+	result := make(map[string]SchemaField)
+
+	for _, section := range sections {
+		section = strings.TrimSuffix(strings.TrimSpace(section), ".*")
+		for key, field := range schemaFields {
+			if section == "*" || key == section || strings.HasPrefix(key, section+".") {
+				result[key] = field
+			}
+		}
+	}
+
+	return result
 }
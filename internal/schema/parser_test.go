@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseYAMLSchemaRejectsMismatchedValues(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{
+			name: "unquoted yes and no on string field",
+			yaml: "answer:\n  type: string\n  enum: [yes, no]\n",
+			want: "field answer: enum value true does not match type string",
+		},
+		{
+			name: "numeric enum on string field",
+			yaml: "code:\n  type: string\n  enum: [1, 2]\n",
+			want: "field code: enum value 1 does not match type string",
+		},
+		{
+			name: "string example on int field",
+			yaml: "age:\n  type: int\n  examples: [тридцать]\n",
+			want: "field age: examples value тридцать does not match type int",
+		},
+		{
+			name: "fractional enum on int field",
+			yaml: "level:\n  type: int\n  enum: [1.5]\n",
+			want: "field level: enum value 1.5 does not match type int",
+		},
+		{
+			name: "enum checked against array item type",
+			yaml: "career.skills:\n  type: array<string>\n  enum: [go, 1]\n",
+			want: "field career.skills: enum value 1 does not match type string",
+		},
+		{
+			name: "example with unknown object key",
+			yaml: "career.jobs:\n  type: \"array<{company: string}>\"\n  examples: [{title: x}]\n",
+			want: "field career.jobs: examples value map[title:x] does not match type {company: string}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseYAMLSchema([]byte(tt.yaml))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLSchemaAcceptsMatchingValues(t *testing.T) {
+	yaml := `answer:
+  type: string
+  enum: ["yes", "no"]
+age:
+  type: int
+  examples: [30]
+score:
+  type: float
+  enum: [1, 2.5]
+career.skills:
+  type: array<string>
+  enum: [go, sql]
+career.jobs:
+  type: "array<{company: string, from: int}>"
+  examples: [{company: Яндекс, from: 2019}, {company: VK, from: null}]
+tags:
+  type: array
+  examples: [1, x]
+`
+	fields, err := ParseYAMLSchema([]byte(yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fields["answer"].Enum; len(got) != 2 || got[0] != "yes" {
+		t.Errorf("answer enum = %v, want [yes no]", got)
+	}
+	if got := fields["age"].Examples; len(got) != 1 || got[0] != float64(30) {
+		t.Errorf("age examples = %v, want [30]", got)
+	}
+}
+
+func TestParseYAMLSchemaRejectsLeafPrefix(t *testing.T) {
+	_, err := ParseYAMLSchema([]byte("a: string\na.b: int\n"))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	want := "field a conflicts with field a.b"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+
+	if _, err := ParseYAMLSchema([]byte("a.b: string\na.c: int\nab: bool\n")); err != nil {
+		t.Errorf("siblings and similar names: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// parseTypeExpr разбирает тип поля: имя типа (string, int, ...), массив
+// с типом элементов array<T> или объект с набором ключей {key: T, ...}.
+// Например: array<string>, array<{company: string, role: string, from: int, to: int}>.
+func parseTypeExpr(expr string) (SchemaField, error) {
+	p := &typeParser{input: expr}
+	field, err := p.parseType()
+	if err != nil {
+		return SchemaField{}, fmt.Errorf("invalid type %q: %w", expr, err)
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return SchemaField{}, fmt.Errorf("invalid type %q: unexpected %q at position %d", expr, p.input[p.pos:], p.pos)
+	}
+	return field, nil
+}
+
+type typeParser struct {
+	input string
+	pos   int
+}
+
+func (p *typeParser) parseType() (SchemaField, error) {
+	p.skipSpaces()
+	if p.consume('{') {
+		return p.parseObject()
+	}
+
+	name := p.identifier()
+	if name == "" {
+		return SchemaField{}, fmt.Errorf("expected type name at position %d", p.pos)
+	}
+	if name == "array" {
+		field := SchemaField{Type: "array", IsArray: true}
+		p.skipSpaces()
+		if !p.consume('<') {
+			return field, nil
+		}
+		items, err := p.parseType()
+		if err != nil {
+			return SchemaField{}, err
+		}
+		p.skipSpaces()
+		if !p.consume('>') {
+			return SchemaField{}, fmt.Errorf("expected '>' at position %d", p.pos)
+		}
+		field.Items = &items
+		return field, nil
+	}
+
+	field := SchemaField{Type: name}
+	if name == "object" {
+		field.IsObject = true
+	}
+	return field, nil
+}
+
+// parseObject разбирает ключи объекта после '{'
+func (p *typeParser) parseObject() (SchemaField, error) {
+	field := SchemaField{Type: "object", IsObject: true, Nested: make(map[string]SchemaField)}
+
+	for {
+		p.skipSpaces()
+		if p.consume('}') {
+			break
+		}
+		if len(field.Nested) > 0 && !p.consume(',') {
+			return SchemaField{}, fmt.Errorf("expected ',' or '}' at position %d", p.pos)
+		}
+
+		p.skipSpaces()
+		key := p.identifier()
+		if key == "" {
+			return SchemaField{}, fmt.Errorf("expected key at position %d", p.pos)
+		}
+		if _, exists := field.Nested[key]; exists {
+			return SchemaField{}, fmt.Errorf("duplicate key %q", key)
+		}
+		p.skipSpaces()
+		if !p.consume(':') {
+			return SchemaField{}, fmt.Errorf("expected ':' after key %q", key)
+		}
+
+		value, err := p.parseType()
+		if err != nil {
+			return SchemaField{}, err
+		}
+		value.Name = key
+		field.Nested[key] = value
+	}
+
+	if len(field.Nested) == 0 {
+		return SchemaField{}, fmt.Errorf("object type has no keys")
+	}
+	return field, nil
+}
+
+func (p *typeParser) identifier() string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if r != '_' && r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		p.pos += size
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *typeParser) consume(c byte) bool {
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *typeParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// checkTypes проверяет, что тип поля и типы его элементов и ключей известны
+func checkTypes(field SchemaField) error {
+	if !fieldTypes[field.Type] {
+		return fmt.Errorf("unknown type %q", field.Type)
+	}
+	if field.Items != nil {
+		if err := checkTypes(*field.Items); err != nil {
+			return err
+		}
+	}
+	for _, key := range field.NestedKeys() {
+		if err := checkTypes(field.Nested[key]); err != nil {
+			return fmt.Errorf("key %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// TypeString записывает тип поля в синтаксисе словаря: array<{company: string, from: int}>.
+// Ключи объекта перечисляются по алфавиту.
+func (s SchemaField) TypeString() string {
+	switch {
+	case s.Items != nil:
+		return "array<" + s.Items.TypeString() + ">"
+	case len(s.Nested) > 0:
+		parts := make([]string, 0, len(s.Nested))
+		for _, key := range s.NestedKeys() {
+			parts = append(parts, key+": "+s.Nested[key].TypeString())
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	default:
+		return s.Type
+	}
+}
+
+// NestedKeys возвращает ключи объектного типа по алфавиту
+func (s SchemaField) NestedKeys() []string {
+	keys := make([]string, 0, len(s.Nested))
+	for key := range s.Nested {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
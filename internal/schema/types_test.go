@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTypeExpr(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"string", "string"},
+		{"array", "array"},
+		{"array<int>", "array<int>"},
+		{" array < string > ", "array<string>"},
+		{"array<{company: string, from: int}>", "array<{company: string, from: int}>"},
+		{"array<{to: int, from: int}>", "array<{from: int, to: int}>"},
+		{"array<{name: string, jobs: array<{company: string, role: string}>}>", "array<{jobs: array<{company: string, role: string}>, name: string}>"},
+		{"{город: string, улица: string}", "{город: string, улица: string}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			field, err := parseTypeExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("parseTypeExpr(%q): %v", tt.expr, err)
+			}
+			if err := checkTypes(field); err != nil {
+				t.Fatalf("checkTypes(%q): %v", tt.expr, err)
+			}
+			if got := field.TypeString(); got != tt.want {
+				t.Errorf("TypeString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTypeExprNested(t *testing.T) {
+	field, err := parseTypeExpr("array<{company: string, projects: array<{name: string, year: int}>}>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !field.IsArray || field.Items == nil || !field.Items.IsObject {
+		t.Fatalf("field = %+v, want array of objects", field)
+	}
+	projects := field.Items.Nested["projects"]
+	if projects.Name != "projects" || projects.Items == nil {
+		t.Fatalf("projects = %+v, want named array with items", projects)
+	}
+	if year := projects.Items.Nested["year"]; year.Type != "int" || year.Name != "year" {
+		t.Errorf("projects item year = %+v, want int named year", year)
+	}
+}
+
+func TestParseTypeExprMalformed(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "expected type name"},
+		{"array<string", "expected '>'"},
+		{"array<>", "expected type name"},
+		{"array<string>>", "unexpected"},
+		{"{}", "object type has no keys"},
+		{"{company string}", "expected ':' after key"},
+		{"{company: string role: string}", "expected ',' or '}'"},
+		{"{company: string, company: int}", `duplicate key "company"`},
+		{"{: string}", "expected key"},
+		{"string int", "unexpected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := parseTypeExpr(tt.expr)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTypesUnknown(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"integer", `unknown type "integer"`},
+		{"array<text>", `unknown type "text"`},
+		{"array<{company: str}>", `key company: unknown type "str"`},
+		{"{jobs: array<{from: date}>}", `key jobs: key from: unknown type "date"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			field, err := parseTypeExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("parseTypeExpr(%q): %v", tt.expr, err)
+			}
+			err = checkTypes(field)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if err.Error() != tt.want {
+				t.Errorf("error = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -2,13 +2,62 @@ package validator
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"profile-extractor/internal/schema"
 )
 
+// ValidationErrors — все нарушения схемы, найденные в профиле
+type ValidationErrors []error
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, err := range v {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Messages возвращает тексты ошибок, например для передачи модели
+func (v ValidationErrors) Messages() []string {
+	messages := make([]string, len(v))
+	for i, err := range v {
+		messages[i] = err.Error()
+	}
+	return messages
+}
+
+// Repairable возвращает нарушения, которые модель может исправить по самому
+// профилю. Пустые обязательные поля сюда не входят: заполнить их можно только
+// выдумав значение, которого нет в интервью.
+func (v ValidationErrors) Repairable() ValidationErrors {
+	var errs ValidationErrors
+	for _, err := range v {
+		var required *RequiredError
+		if !errors.As(err, &required) {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// RequiredError — обязательное поле отсутствует или равно null
+type RequiredError struct {
+	Field string
+}
+
+func (e *RequiredError) Error() string {
+	return fmt.Sprintf("field %s is required", e.Field)
+}
+
+// ValidateProfileJSON проверяет профиль по схеме. Если профиль — валидный JSON,
+// но нарушает схему, возвращается ValidationErrors со всеми нарушениями.
 func ValidateProfileJSON(jsonStr string, schemaFields map[string]schema.SchemaField) error {
 	// Проверка валидности JSON
 	var profile map[string]interface{}
@@ -16,31 +65,198 @@ func ValidateProfileJSON(jsonStr string, schemaFields map[string]schema.SchemaFi
 		return fmt.Errorf("invalid JSON: %w", err)
 	}
 
+	var errs ValidationErrors
+
 	// Проверка типов данных
-	for key, field := range schemaFields {
+	for _, key := range sortedKeys(schemaFields) {
+		if strings.Contains(key, ".") {
+			continue
+		}
 		if value, exists := profile[key]; exists && value != nil {
-			if err := validateFieldType(value, field, key); err != nil {
-				return fmt.Errorf("field %s: %w", key, err)
+			if err := validateFieldType(value, schemaFields[key], key); err != nil {
+				errs = append(errs, fmt.Errorf("field %s: %w", key, err))
 			}
 		}
 	}
 
 	// Проверка вложенных объектов для точечной нотации
-	if err := validateNestedFields(profile, schemaFields); err != nil {
-		return fmt.Errorf("nested fields validation: %w", err)
-	}
+	errs = append(errs, validateNestedFields(profile, schemaFields)...)
+
+	// Обязательные поля
+	errs = append(errs, validateRequired(profile, schemaFields)...)
 
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
+func sortedKeys(schemaFields map[string]schema.SchemaField) []string {
+	keys := make([]string, 0, len(schemaFields))
+	for key := range schemaFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func validateFieldType(value interface{}, field schema.SchemaField, fieldName string) error {
 	// Обработка точечной нотации - НЕ рекурсивно!
-	if strings.Contains(fieldName, ".") {
-		// Для точечной нотации просто проверяем базовый тип значения
-		return validateBasicType(value, field.Type)
+	// Для точечной нотации просто проверяем базовый тип значения
+	if err := validateBasicType(value, field.Type); err != nil {
+		return err
+	}
+	if err := validateShape(value, field); err != nil {
+		return err
 	}
 
-	return validateBasicType(value, field.Type)
+	return validateConstraints(value, field)
+}
+
+// validateShape проверяет элементы массива array<T> и ключи объекта {key: T}.
+// Тип самого значения уже проверен.
+func validateShape(value interface{}, field schema.SchemaField) error {
+	if array, ok := value.([]interface{}); ok && field.Items != nil {
+		for i, item := range array {
+			if err := validateItem(item, *field.Items); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+	}
+
+	if object, ok := value.(map[string]interface{}); ok && len(field.Nested) > 0 {
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, known := field.Nested[key]; !known {
+				return fmt.Errorf("unexpected key %q, expected %s", key, field.TypeString())
+			}
+		}
+
+		// Отсутствующий ключ равносилен null
+		for _, key := range field.NestedKeys() {
+			if keyValue := object[key]; keyValue != nil {
+				if err := validateItem(keyValue, field.Nested[key]); err != nil {
+					return fmt.Errorf("key %s: %w", key, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateItem проверяет элемент массива или значение ключа объекта
+func validateItem(value interface{}, field schema.SchemaField) error {
+	if value == nil {
+		return fmt.Errorf("expected %s, got null", field.TypeString())
+	}
+	if err := validateBasicType(value, field.Type); err != nil {
+		return err
+	}
+	return validateShape(value, field)
+}
+
+// validateConstraints проверяет ограничения расширенной формы поля: enum,
+// min/max и pattern. Тип значения уже проверен.
+func validateConstraints(value interface{}, field schema.SchemaField) error {
+	if len(field.Enum) > 0 {
+		items := []interface{}{value}
+		if array, ok := value.([]interface{}); ok {
+			items = array
+		}
+		for _, item := range items {
+			if !inEnum(item, field.Enum) {
+				return fmt.Errorf("value %v is not one of %s", formatValue(item), formatEnum(field.Enum))
+			}
+		}
+	}
+
+	if field.Min != nil || field.Max != nil {
+		size, unit := valueSize(value)
+		if field.Min != nil && size < *field.Min {
+			return fmt.Errorf("%s %v is less than min %v", unit, size, *field.Min)
+		}
+		if field.Max != nil && size > *field.Max {
+			return fmt.Errorf("%s %v is greater than max %v", unit, size, *field.Max)
+		}
+	}
+
+	if s, ok := value.(string); ok && field.PatternRegexp != nil {
+		if !field.PatternRegexp.MatchString(s) {
+			return fmt.Errorf("value %q does not match pattern %s", s, field.Pattern)
+		}
+	}
+
+	return nil
+}
+
+// valueSize возвращает то, что ограничивают min и max: число, длину строки
+// в символах или число элементов массива
+func valueSize(value interface{}) (float64, string) {
+	switch v := value.(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v)), "length"
+	case []interface{}:
+		return float64(len(v)), "item count"
+	case float64:
+		return v, "value"
+	default:
+		return 0, "value"
+	}
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		values[i] = formatValue(value)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+func formatValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(value)
+}
+
+// validateRequired сообщает об обязательных полях, которые отсутствуют или равны null
+func validateRequired(profile map[string]interface{}, schemaFields map[string]schema.SchemaField) ValidationErrors {
+	var errs ValidationErrors
+
+	for _, key := range sortedKeys(schemaFields) {
+		if !schemaFields[key].Required {
+			continue
+		}
+
+		var value interface{} = profile
+		for _, part := range strings.Split(key, ".") {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = object[part]
+		}
+		if value == nil {
+			errs = append(errs, &RequiredError{Field: key})
+		}
+	}
+
+	return errs
 }
 
 func validateBasicType(value interface{}, fieldType string) error {
@@ -85,36 +301,52 @@ func validateDotNotationField(value interface{}, field schema.SchemaField, field
 	return validateBasicType(value, field.Type)
 }
 
-func validateNestedFields(profile map[string]interface{}, schemaFields map[string]schema.SchemaField) error {
-	for key, field := range schemaFields {
+func validateNestedFields(profile map[string]interface{}, schemaFields map[string]schema.SchemaField) ValidationErrors {
+	var errs ValidationErrors
+	reported := make(map[string]bool)
+
+	for _, key := range sortedKeys(schemaFields) {
 		if !strings.Contains(key, ".") {
 			continue
 		}
 
 		// Проверяем, что поля с точечной нотацией действительно создали вложенные объекты
 		parts := strings.Split(key, ".")
-		if len(parts) != 2 {
-			continue // Поддерживаем только одноуровневую вложенность
-		}
+		current := profile
+		found := true
 
-		parentKey := parts[0]
-		childKey := parts[1]
+		for i, part := range parts[:len(parts)-1] {
+			value, exists := current[part]
+			if !exists || value == nil {
+				found = false
+				break
+			}
 
-		if parentValue, exists := profile[parentKey]; exists && parentValue != nil {
-			parentObj, ok := parentValue.(map[string]interface{})
+			obj, ok := value.(map[string]interface{})
 			if !ok {
-				return fmt.Errorf("field %s should be an object for nested field %s", parentKey, key)
+				parentKey := strings.Join(parts[:i+1], ".")
+				if !reported[parentKey] {
+					reported[parentKey] = true
+					errs = append(errs, fmt.Errorf("field %s should be an object for nested field %s", parentKey, key))
+				}
+				found = false
+				break
 			}
+			current = obj
+		}
 
-			if childValue, childExists := parentObj[childKey]; childExists && childValue != nil {
-				if err := validateFieldType(childValue, field, key); err != nil {
-					return fmt.Errorf("nested field %s: %w", key, err)
-				}
+		if !found {
+			continue
+		}
+
+		if childValue, childExists := current[parts[len(parts)-1]]; childExists && childValue != nil {
+			if err := validateFieldType(childValue, schemaFields[key], key); err != nil {
+				errs = append(errs, fmt.Errorf("nested field %s: %w", key, err))
 			}
 		}
 	}
 
-	return nil
+	return errs
 }
 
 func PrettyPrintValidationResult(jsonStr string) {
@@ -0,0 +1,120 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"profile-extractor/internal/schema"
+)
+
+func TestValidateProfileJSONEnum(t *testing.T) {
+	fields, err := schema.ParseYAMLSchema([]byte(`answer:
+  type: string
+  enum: ["yes", "no"]
+career.skills:
+  type: array<string>
+  enum: [go, sql]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		profile string
+		wantErr string
+	}{
+		{"matching values", `{"answer": "yes", "career": {"skills": ["go", "sql"]}}`, ""},
+		{"null is allowed", `{"answer": null}`, ""},
+		{"value outside enum", `{"answer": "maybe"}`, `field answer: value "maybe" is not one of ["yes", "no"]`},
+		{"array item outside enum", `{"career": {"skills": ["go", "rust"]}}`, `field career.skills: value "rust" is not one of ["go", "sql"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProfileJSON(tt.profile, fields)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("error = %v, want ValidationErrors", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateShape(t *testing.T) {
+	fields, err := schema.ParseYAMLSchema([]byte(`career.skills: "array<string>"
+career.path: "array<{company: string, from: int, projects: array<{name: string}>}>"
+location: "{city: string, country: string}"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		field   string
+		value   interface{}
+		wantErr string
+	}{
+		{"typed items", "career.skills", []interface{}{"go", "sql"}, ""},
+		{"item of wrong type", "career.skills", []interface{}{"go", 1.0}, "item 1: expected string, got float64"},
+		{"null item", "career.skills", []interface{}{nil}, "item 0: expected string, got null"},
+		{
+			"nested objects",
+			"career.path",
+			[]interface{}{map[string]interface{}{
+				"company":  "Яндекс",
+				"from":     2019.0,
+				"projects": []interface{}{map[string]interface{}{"name": "Поиск"}},
+			}},
+			"",
+		},
+		{"missing and null keys", "career.path", []interface{}{map[string]interface{}{"company": nil}}, ""},
+		{
+			"unknown key",
+			"career.path",
+			[]interface{}{map[string]interface{}{"company": "VK", "title": "dev"}},
+			`item 0: unexpected key "title", expected {company: string, from: int, projects: array<{name: string}>}`,
+		},
+		{
+			"key of wrong type",
+			"career.path",
+			[]interface{}{map[string]interface{}{"from": "2019"}},
+			"item 0: key from: expected number, got string",
+		},
+		{
+			"null item in nested array",
+			"career.path",
+			[]interface{}{map[string]interface{}{"projects": []interface{}{nil}}},
+			"item 0: key projects: item 0: expected {name: string}, got null",
+		},
+		{"item that is not an object", "career.path", []interface{}{"Яндекс"}, "item 0: expected object, got string"},
+		{"object keys", "location", map[string]interface{}{"city": "Казань"}, ""},
+		{"unknown object key", "location", map[string]interface{}{"street": "x"}, `unexpected key "street"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateShape(tt.value, fields[tt.field])
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
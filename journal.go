@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"profile-extractor/pkg/extractor"
+)
+
+// Состояния интервью в журнале пакетной обработки
+const (
+	stagePending    = "pending"
+	stageExtracting = string(extractor.StageExtracting)
+	stageValidating = string(extractor.StageValidating)
+	stageRepairing  = string(extractor.StageRepairing)
+	stageDone       = "done"
+	stageFailed     = "failed"
+)
+
+// journalEntry — запись о смене состояния интервью. Во время запуска записи
+// только добавляются, актуально последнее состояние файла; при открытии и
+// закрытии журнал сжимается до одной записи на файл.
+type journalEntry struct {
+	Path        string `json:"path"`
+	InterviewID string `json:"interview_id,omitempty"`
+	// Checksum — sha256 файла интервью; измененный файл обрабатывается заново
+	Checksum string `json:"checksum,omitempty"`
+	State    string `json:"state"`
+	// Extraction — результат этапа 1, записывается при переходе к валидации
+	Extraction *extractor.Extraction `json:"extraction,omitempty"`
+	Output     string                `json:"output,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	Time       time.Time             `json:"time"`
+}
+
+// jobJournal — журнал пакетной обработки в JSONL. По нему повторный запуск
+// пропускает готовые интервью и продолжает остальные с последнего
+// завершенного этапа.
+type jobJournal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	enc  *json.Encoder
+	// last — последнее состояние по пути файла
+	last map[string]*journalEntry
+	// extractions — последний результат этапа 1 по пути файла
+	extractions map[string]*journalEntry
+}
+
+// openJournal читает существующий журнал и открывает его для дописывания
+func openJournal(path string) (*jobJournal, error) {
+	j := &jobJournal{
+		path:        path,
+		last:        make(map[string]*journalEntry),
+		extractions: make(map[string]*journalEntry),
+	}
+
+	if err := j.load(path); err != nil {
+		return nil, err
+	}
+	// Прерванные запуски оставляют по несколько записей на файл
+	if err := j.compact(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening job journal: %w", err)
+	}
+	j.file = file
+	j.enc = json.NewEncoder(file)
+
+	return j, nil
+}
+
+func (j *jobJournal) load(path string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading job journal: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// Последняя строка может быть оборвана при аварийном завершении
+			log.Printf("Job journal line %d skipped: %v", line, err)
+			continue
+		}
+		j.apply(&entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading job journal: %w", err)
+	}
+
+	return nil
+}
+
+func (j *jobJournal) apply(entry *journalEntry) {
+	j.last[entry.Path] = entry
+	switch {
+	case entry.Extraction != nil:
+		j.extractions[entry.Path] = entry
+	case entry.State == stageExtracting:
+		// Извлечение началось заново, старый результат устарел
+		delete(j.extractions, entry.Path)
+	}
+}
+
+// Record дописывает запись в журнал
+func (j *jobJournal) Record(entry journalEntry) error {
+	entry.Time = time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.apply(&entry)
+	if err := j.enc.Encode(&entry); err != nil {
+		return err
+	}
+	// Запись должна пережить аварийное завершение процесса
+	return j.file.Sync()
+}
+
+// Last возвращает последнее состояние файла или nil
+func (j *jobJournal) Last(path string) *journalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.last[path]
+}
+
+// Extraction возвращает сохраненный результат этапа 1 для той же версии файла
+func (j *jobJournal) Extraction(path, checksum string) *extractor.Extraction {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry, ok := j.extractions[path]
+	if !ok || entry.Checksum != checksum {
+		return nil
+	}
+	return entry.Extraction
+}
+
+// Close закрывает журнал и сжимает записи этого запуска
+func (j *jobJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+	return j.compact()
+}
+
+// compact переписывает журнал по одной записи на файл: последнее состояние,
+// а для незавершенного интервью — вместе с сохраненным результатом этапа 1
+// той же версии файла, чтобы по нему можно было продолжить.
+func (j *jobJournal) compact() error {
+	if len(j.last) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(j.last))
+	for path := range j.last {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	tmpPath := j.path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("error compacting job journal: %w", err)
+	}
+	enc := json.NewEncoder(file)
+	for _, path := range paths {
+		entry := *j.last[path]
+		if entry.State == stageDone {
+			entry.Extraction = nil
+		} else if saved, ok := j.extractions[path]; ok && entry.Extraction == nil && saved.Checksum == entry.Checksum {
+			entry.Extraction = saved.Extraction
+		}
+		if err := enc.Encode(&entry); err != nil {
+			file.Close()
+			return fmt.Errorf("error compacting job journal: %w", err)
+		}
+	}
+	// Сжатый журнал заменяет старый, только когда записан целиком
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("error compacting job journal: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error compacting job journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		return fmt.Errorf("error compacting job journal: %w", err)
+	}
+	return nil
+}
+
+// journalKey — путь файла в журнале; абсолютный, чтобы запуск из другого
+// каталога или с другим шаблоном находил те же записи
+func journalKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// fileChecksum возвращает sha256 содержимого файла
+func fileChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
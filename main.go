@@ -1,155 +1,369 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"profile-extractor/internal/api"
+	"profile-extractor/internal/config"
 	"profile-extractor/internal/interview"
-	"profile-extractor/internal/prompts"
 	"profile-extractor/internal/schema"
-	"profile-extractor/internal/validator"
+	"profile-extractor/pkg/extractor"
 
 	"github.com/joho/godotenv"
 )
 
+// Коды завершения
+const (
+	exitOK = 0
+	// exitError — ошибка обработки, конфигурации или провайдера
+	exitError = 1
+	// exitUsage — неверная команда, флаги или аргументы
+	exitUsage = 2
+	// exitInvalid — профиль не соответствует схеме (validate)
+	exitInvalid = 3
+	// exitPartial — часть интервью не обработана (batch)
+	exitPartial = 4
+	// exitInterrupted — прервано пользователем, как принято для SIGINT
+	exitInterrupted = 130
+)
+
+// command — подкоманда CLI
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"extract", "извлечь профиль из интервью", runExtract},
+	{"validate", "проверить профиль по схеме", runValidate},
+	{"batch", "обработать несколько интервью", runBatch},
+	{"schema", "вывести JSON Schema или список полей словаря", runSchema},
+	{"serve", "HTTP сервер для извлечения профилей", runServe},
+	{"render", "вывести профиль в Markdown", runRender},
+}
+
 func main() {
-	// Загрузка переменных окружения
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
+	// Ctrl-C и SIGTERM отменяют контекст и прерывают текущий запрос к модели
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+// run выполняет подкоманду и возвращает код завершения
+func run(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return exitUsage
 	}
 
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		log.Fatal("OPENAI_API_KEY not found in environment")
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		printUsage(os.Stdout)
+		return exitOK
 	}
 
-	// Чтение YAML схемы
-	yamlContent, err := ioutil.ReadFile("config/dictionary.yaml")
-	if err != nil {
-		log.Fatal("Error reading dictionary.yaml:", err)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return exitCode(cmd.run(ctx, args[1:]))
+		}
 	}
 
-	// Парсинг схемы
-	schemaFields, err := schema.ParseYAMLSchema(yamlContent)
-	if err != nil {
-		log.Fatal("Error parsing schema:", err)
+	// Прежний вызов profile-extractor interview.json
+	if strings.HasSuffix(name, ".json") {
+		return exitCode(runExtract(ctx, args))
 	}
 
-	log.Printf("Loaded schema with %d fields", len(schemaFields))
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+	printUsage(os.Stderr)
+	return exitUsage
+}
 
-	// Чтение JSON файла интервью
-	interviewPath := "input/interview.json"
-	if len(os.Args) > 1 {
-		interviewPath = os.Args[1]
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Использование: profile-extractor <команда> [флаги] [аргументы]")
+	fmt.Fprintln(w, "\nКоманды:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
 	}
+	fmt.Fprintln(w, "\nФлаги команды: profile-extractor <команда> -h")
+	fmt.Fprintln(w, "\nКоды завершения:")
+	fmt.Fprintln(w, "  0    успешно")
+	fmt.Fprintln(w, "  1    ошибка обработки, конфигурации или провайдера")
+	fmt.Fprintln(w, "  2    неверная команда, флаги или аргументы")
+	fmt.Fprintln(w, "  3    профиль не соответствует схеме (validate)")
+	fmt.Fprintln(w, "  4    часть интервью не обработана (batch)")
+	fmt.Fprintln(w, "  130  прервано (Ctrl-C)")
+}
 
-	interviewData, err := ioutil.ReadFile(interviewPath)
-	if err != nil {
-		log.Fatal("Error reading interview file:", err)
+// cliError задает код завершения для ошибки. Пустой err означает, что
+// сообщение уже выведено.
+type cliError struct {
+	code int
+	err  error
+}
+
+func (e *cliError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit code %d", e.code)
 	}
+	return e.err.Error()
+}
 
-	// Парсинг интервью
-	interviewObj, err := interview.ParseInterviewJSON(interviewData)
-	if err != nil {
-		log.Fatal("Error parsing interview JSON:", err)
+func (e *cliError) Unwrap() error {
+	return e.err
+}
+
+func usageError(format string, args ...interface{}) error {
+	return &cliError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+// exitCode выводит ошибку и возвращает соответствующий ей код завершения
+func exitCode(err error) int {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "Interrupted")
+		return exitInterrupted
 	}
 
-	log.Printf("Loaded interview: %s", interviewObj.InterviewID)
+	code := exitError
+	var cliErr *cliError
+	if errors.As(err, &cliErr) {
+		code = cliErr.code
+		if cliErr.err == nil {
+			return code
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	return code
+}
 
-	// Извлечение текста из ответов интервью
-	// Можно выбрать разные способы извлечения:
+// options — флаги, общие для команд
+type options struct {
+	configPath string
+	schemaPath string
+	quiet      bool
+	verbose    bool
+
+	// Флаги команд, которые обращаются к модели
+	model   string
+	method  string
+	noCache bool
+}
 
-	// 1. Все ответы подряд
-	// userText := interviewObj.ExtractAllAnswers()
+// commonFlags регистрирует флаги конфигурации, словаря и подробности вывода
+func commonFlags(flags *flag.FlagSet) *options {
+	o := &options{}
+	flags.StringVar(&o.configPath, "config", "config/config.yaml", "файл конфигурации")
+	flags.StringVar(&o.schemaPath, "schema", "config/dictionary.yaml", "словарь полей профиля")
+	flags.BoolVar(&o.quiet, "q", false, "выводить только ошибки")
+	flags.BoolVar(&o.verbose, "v", false, "выводить промпты и промежуточные профили")
+	return o
+}
 
-	// 2. Ответы с контекстом вопросов (рекомендуется)
-	userText := interviewObj.ExtractContextualAnswers()
+// modelFlags регистрирует флаги команд, которые обращаются к модели
+func (o *options) modelFlags(flags *flag.FlagSet) {
+	flags.StringVar(&o.model, "model", "", "модель для всех этапов вместо заданных в конфигурации")
+	flags.StringVar(&o.method, "method", "",
+		"способ построения текста интервью (по умолчанию extraction.text_method): "+strings.Join(interview.TextBuilderNames(), ", "))
+	flags.BoolVar(&o.noCache, "no-cache", false, "не использовать кэш ответов модели")
+}
 
-	// 3. Ответы по блокам (для дополнительной обработки)
-	// blockAnswers := interviewObj.ExtractAnswersByBlock()
+// newFlagSet создает набор флагов команды; ошибки разбора выводит сам flag
+func newFlagSet(name, synopsis string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Использование: profile-extractor %s %s\n\nФлаги:\n", name, synopsis)
+		flags.PrintDefaults()
+	}
+	return flags
+}
 
-	log.Printf("Extracted text length: %d characters", len(userText))
-	log.Println("Sample extracted text (first 200 chars):")
-	if len(userText) > 200 {
-		log.Println(userText[:200] + "...")
-	} else {
-		log.Println(userText)
+// parseFlags разбирает флаги команды. Флаги можно указывать и после
+// аргументов; все, что идет после "--", считается аргументами.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return err
+			}
+			return &cliError{code: exitUsage}
+		}
+
+		rest := flags.Args()
+		consumed := len(args) - len(rest)
+		if len(rest) == 0 || (consumed > 0 && args[consumed-1] == "--") {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
 	}
 
-	// Создание клиента API
-	client := api.NewOpenAIClient(apiKey)
+	// Повторный разбор оставляет в flags.Args() только аргументы
+	return flags.Parse(append([]string{"--"}, positional...))
+}
 
-	// Этап 1: Извлечение данных
-	log.Println("\nStep 1: Extracting profile data from interview...")
-	extractionPrompt := prompts.GenerateExtractionPrompt(schemaFields, userText)
+// setup применяет флаги вывода: -q оставляет только ошибки
+func (o *options) setup() error {
+	if o.quiet && o.verbose {
+		return usageError("flags -q and -v are mutually exclusive")
+	}
+	if o.quiet {
+		log.SetOutput(io.Discard)
+	}
+	return nil
+}
 
-	log.Println("Generated extraction prompt:")
-	log.Println("---")
-	log.Println(extractionPrompt[:500] + "...")
-	log.Println("---")
+// loadConfig загружает .env и конфигурацию
+func (o *options) loadConfig() (*config.Config, error) {
+	// Без .env можно работать, например в CI с кассетой или с ключами из окружения
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error loading .env file: %w", err)
+	}
 
-	profileJSON, err := client.ExtractProfile(extractionPrompt)
+	cfg, err := config.Load(o.configPath)
 	if err != nil {
-		log.Fatal("Error extracting profile:", err)
+		return nil, fmt.Errorf("error loading config: %w", err)
+	}
+
+	if o.method != "" {
+		cfg.Extraction.TextMethod = o.method
+	}
+	if o.model != "" {
+		cfg.Stages.Extraction.Model = o.model
+		cfg.Stages.Validation.Model = o.model
 	}
 
-	log.Println("Extracted profile:")
-	log.Println(profileJSON)
+	return cfg, nil
+}
 
-	// Этап 2: Валидация и очистка
-	log.Println("\nStep 2: Validating and cleaning profile...")
-	validationPrompt := prompts.GenerateValidationPrompt(profileJSON)
+// loadSchema читает и разбирает словарь полей профиля
+func (o *options) loadSchema() (map[string]schema.SchemaField, error) {
+	yamlContent, err := ioutil.ReadFile(o.schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema: %w", err)
+	}
 
-	validatedJSON, err := client.ExtractProfile(validationPrompt)
+	schemaFields, err := schema.ParseYAMLSchema(yamlContent)
 	if err != nil {
-		log.Fatal("Error validating profile:", err)
+		return nil, fmt.Errorf("error parsing schema: %w", err)
 	}
 
-	log.Println("Validated profile:")
-	log.Println(validatedJSON)
+	log.Printf("Loaded schema with %d fields", len(schemaFields))
+	return schemaFields, nil
+}
 
-	// Финальная проверка структуры
-	if err := validator.ValidateProfileJSON(validatedJSON, schemaFields); err != nil {
-		log.Printf("Validation warning: %v", err)
+// extractor создает Extractor по конфигурации, словарю и флагам. Промпты,
+// промежуточные профили и прогресс потока выводятся только при interactive:
+// в пакетном режиме и в сервере вывод нескольких интервью перемешался бы.
+func (o *options) extractor(interactive bool) (*extractor.Extractor, error) {
+	if err := o.setup(); err != nil {
+		return nil, err
+	}
+	if o.method != "" {
+		if _, err := interview.LookupTextBuilder(o.method); err != nil {
+			return nil, &cliError{code: exitUsage, err: err}
+		}
 	}
 
-	// Форматирование JSON для читаемости
-	var formatted map[string]interface{}
-	json.Unmarshal([]byte(validatedJSON), &formatted)
+	cfg, err := o.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	schemaFields, err := o.loadSchema()
+	if err != nil {
+		return nil, err
+	}
 
-	// Добавление метаданных интервью
-	metadata := interviewObj.GetInterviewMetadata()
-	formatted["_metadata"] = map[string]interface{}{
-		"source_interview": metadata,
-		"processing_info": map[string]interface{}{
-			"schema_version":    "1.0",
-			"extraction_method": "contextual_answers",
-			"text_length":       len(userText),
-		},
+	opts := []extractor.Option{
+		extractor.WithConfig(cfg),
+		extractor.WithSchema(schemaFields),
+		// Сообщения библиотеки идут в общий log, который -q отключает
+		extractor.WithLogger(log.Default()),
 	}
+	if interactive {
+		opts = append(opts, extractor.WithHooks(o.hooks()))
+	}
+	if o.noCache {
+		opts = append(opts, extractor.WithoutCache())
+	}
+	return extractor.New(opts...)
+}
 
-	prettyJSON, _ := json.MarshalIndent(formatted, "", "  ")
+// hooks выводит промпты и промежуточные профили с -v и прогресс потоковых ответов
+func (o *options) hooks() extractor.Hooks {
+	var hooks extractor.Hooks
+	if o.verbose {
+		hooks.OnPrompt = func(stage, prompt string) {
+			if len(prompt) > 500 {
+				prompt = prompt[:500] + "..."
+			}
+			log.Printf("Generated %s prompt:\n---\n%s\n---", stage, prompt)
+		}
+		hooks.OnResult = func(stage, json string) {
+			log.Printf("Profile after %s stage:\n%s", stage, json)
+		}
+	}
+	if !o.quiet {
+		hooks.OnProgress = progressPrinter()
+	}
+	return hooks
+}
 
-	// Создание папки output если не существует
-	os.MkdirAll("output", 0755)
+// progressPrinter выводит прогресс потокового ответа одной строкой в stderr
+func progressPrinter() func(stage string, p extractor.Progress) {
+	// Прогресс приходит из нескольких воркеров сразу
+	var mu sync.Mutex
+	var lastPrinted time.Time
+	return func(stage string, p extractor.Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Обновляем строку не чаще четырех раз в секунду
+		if !p.Done && time.Since(lastPrinted) < 250*time.Millisecond {
+			return
+		}
+		lastPrinted = time.Now()
+
+		fmt.Fprintf(os.Stderr, "\r%s: ~%d tokens received in %.1fs", stage, p.Tokens, p.Elapsed.Seconds())
+		if p.Done {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
 
-	// Сохранение результата с ID интервью в имени файла
-	outputFileName := fmt.Sprintf("output/profile_%s.json", interviewObj.InterviewID)
-	err = ioutil.WriteFile(outputFileName, prettyJSON, 0644)
-	if err != nil {
-		log.Fatal("Error saving profile:", err)
+// printUsageSummary выводит потребление токенов и оценку стоимости по этапам
+func printUsageSummary(w io.Writer, usage *api.UsageReport) {
+	fmt.Fprintln(w, "\nИспользование токенов:")
+	for _, name := range usage.StageNames() {
+		printStageUsage(w, name, usage.Stages[name])
 	}
+	printStageUsage(w, "total", &usage.Total)
 
-	fmt.Printf("\n✅ Профиль успешно создан из интервью и сохранен в %s!\n", outputFileName)
-	fmt.Println("\nМетаданные интервью:")
-	metadataJSON, _ := json.MarshalIndent(metadata, "", "  ")
-	fmt.Println(string(metadataJSON))
+	if len(usage.Total.UnpricedModels) > 0 {
+		fmt.Fprintf(w, "Нет цены для моделей: %s (добавьте их в pricing в config.yaml)\n",
+			strings.Join(usage.Total.UnpricedModels, ", "))
+	}
+}
 
-	fmt.Println("\nРезультат:")
-	fmt.Println(string(prettyJSON))
+func printStageUsage(w io.Writer, name string, stage *api.StageUsage) {
+	fmt.Fprintf(w, "  %-10s запросов: %d, из кэша: %d, prompt: %d, completion: %d, всего: %d, ~$%.4f\n",
+		name, stage.Calls, stage.CachedCalls, stage.PromptTokens, stage.CompletionTokens, stage.TotalTokens, stage.CostUSD)
 }
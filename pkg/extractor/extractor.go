@@ -0,0 +1,290 @@
+// Package extractor извлекает психологический профиль из интервью: строит
+// текст интервью, запрашивает модель по частям, объединяет частичные профили,
+// проверяет и исправляет результат. CLI profile-extractor — обертка над этим пакетом.
+//
+//	e, err := extractor.New(extractor.WithConfig(cfg), extractor.WithSchema(fields))
+//	profile, report, err := e.Extract(ctx, interview)
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"profile-extractor/internal/api"
+	"profile-extractor/internal/schema"
+)
+
+// Extractor — все, что нужно для обработки интервью: конфигурация, схема
+// и провайдеры этапов. Один Extractor обслуживает несколько интервью сразу.
+type Extractor struct {
+	cfg         *Config
+	schema      Schema
+	prompts     Prompts
+	validate    Validator
+	textBuilder TextBuilder
+	hooks       Hooks
+	noCache     bool
+	logger      *log.Logger
+
+	extractionProvider Provider
+	validationProvider Provider
+	// responseFormat — схема полного профиля для этапов validation и repair
+	responseFormat *api.ResponseFormat
+	cached         bool
+}
+
+// New создает Extractor. Провайдеры, не заданные опциями, создаются по
+// конфигурации; кэш и лимитер у них общие для всех этапов и интервью, чтобы
+// они делили бюджет провайдера.
+func New(opts ...Option) (*Extractor, error) {
+	e := &Extractor{
+		prompts:  DefaultPrompts{},
+		validate: DefaultValidator,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if len(e.schema) == 0 {
+		return nil, errors.New("extractor: schema is required")
+	}
+	if e.cfg == nil {
+		e.cfg = DefaultConfig()
+	}
+	if e.logger == nil {
+		// Библиотека молчит, пока не передан WithLogger
+		e.logger = log.New(io.Discard, "", 0)
+	}
+
+	if e.textBuilder == nil {
+		textBuilder, err := LookupTextBuilder(e.cfg.Extraction.TextMethod)
+		if err != nil {
+			return nil, err
+		}
+		e.textBuilder = textBuilder
+	}
+
+	if e.extractionProvider == nil || e.validationProvider == nil {
+		if err := e.newProviders(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Нативный JSON режим: схема ответа строится из словаря
+	if e.cfg.StructuredOutput {
+		e.responseFormat = api.JSONSchemaFormat("profile", schema.ToJSONSchema(e.schema))
+	}
+
+	return e, nil
+}
+
+// Config возвращает используемую конфигурацию
+func (e *Extractor) Config() *Config {
+	return e.cfg
+}
+
+// Report — сведения об обработке одного интервью. Возвращается и при ошибке,
+// чтобы был виден расход токенов на неудачные интервью.
+type Report struct {
+	InterviewID string
+	Usage       *UsageReport
+	// Extraction — результат этапа 1; по нему можно продолжить обработку через ResumeFrom
+	Extraction *Extraction
+	// Resumed — этап 1 взят из ResumeFrom
+	Resumed bool
+	// ValidationRepairs — исправления JSON ответа этапа валидации
+	ValidationRepairs []string
+	RepairAttempts    []RepairAttempt
+	// ValidationError — нарушения схемы, оставшиеся после исправлений
+	ValidationError error
+}
+
+// ExtractOption настраивает один вызов Extract
+type ExtractOption func(*extractRun)
+
+type extractRun struct {
+	resume  *Extraction
+	onStage func(Stage, *Extraction)
+}
+
+// ResumeFrom продолжает обработку с валидации по сохраненному результату этапа 1
+func ResumeFrom(extraction *Extraction) ExtractOption {
+	return func(r *extractRun) { r.resume = extraction }
+}
+
+// OnStage сообщает о переходах между этапами этого вызова, например для журнала
+func OnStage(fn func(stage Stage, extraction *Extraction)) ExtractOption {
+	return func(r *extractRun) { r.onStage = fn }
+}
+
+func (e *Extractor) enter(run *extractRun, stage Stage, extraction *Extraction) {
+	if e.hooks.OnStage != nil {
+		e.hooks.OnStage(stage, extraction)
+	}
+	if run.onStage != nil {
+		run.onStage(stage, extraction)
+	}
+}
+
+// Extract извлекает профиль из интервью и проверяет его
+func (e *Extractor) Extract(ctx context.Context, interviewObj *Interview, opts ...ExtractOption) (*Profile, *Report, error) {
+	run := &extractRun{}
+	for _, opt := range opts {
+		opt(run)
+	}
+
+	cfg := e.cfg
+	ctx = api.WithLogger(ctx, e.logger)
+	report := &Report{
+		InterviewID: interviewObj.InterviewID,
+		Usage:       api.NewUsageReport(cfg.Pricing),
+	}
+
+	if cfg.InterviewTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.InterviewTimeout)
+		defer cancel()
+	}
+
+	// Извлечение текста из ответов интервью выбранным способом
+	userText := e.textBuilder.Build(interviewObj)
+	e.logger.Printf("Extracted text length: %d characters", len(userText))
+
+	// Этап 1: Извлечение данных. При продолжении берется сохраненный результат
+	extraction := run.resume
+	report.Resumed = extraction != nil
+	if report.Resumed {
+		e.logger.Println("\nStep 1: Using previously extracted profile")
+	} else {
+		e.enter(run, StageExtracting, nil)
+		var err error
+		extraction, err = e.extract(ctx, interviewObj, report.Usage)
+		if err != nil {
+			return nil, report, fmt.Errorf("error extracting profile: %w", err)
+		}
+	}
+	report.Extraction = extraction
+	e.result("extraction", extraction.JSON)
+
+	// Этап 2: Валидация и очистка
+	e.enter(run, StageValidating, extraction)
+	e.logger.Println("\nStep 2: Validating and cleaning profile...")
+	validationStage := cfg.Stages.Validation
+	validationPrompt := e.prompts.Validation(e.schema, extraction.JSON)
+	e.prompt("validation", validationPrompt)
+
+	validation, err := api.ExtractProfile(e.stageContext(ctx, "validation"), e.validationProvider,
+		validationStage.ModelParams, validationPrompt, e.responseFormat)
+	if err != nil {
+		return nil, report, fmt.Errorf("error validating profile: %w", err)
+	}
+	e.logRepairs("validation", validation)
+	report.Usage.Record("validation", validation)
+	report.ValidationRepairs = validation.Repairs
+	e.result("validation", validation.JSON)
+	validated, err := decodeObject([]byte(validation.JSON))
+	if err != nil {
+		return nil, report, fmt.Errorf("error parsing validated profile: %w", err)
+	}
+
+	// Финальная проверка структуры и исправление ошибок моделью
+	finalJSON, attempts, err := e.repairProfile(ctx, run, validation.JSON, report.Usage)
+	report.RepairAttempts = attempts
+	if err != nil {
+		return nil, report, fmt.Errorf("error repairing profile: %w", err)
+	}
+	if err := e.validate(finalJSON, e.schema); err != nil {
+		e.logger.Printf("Validation warning: %v", err)
+		report.ValidationError = err
+	}
+
+	data, err := decodeObject([]byte(finalJSON))
+	if err != nil {
+		return nil, report, fmt.Errorf("error parsing repaired profile: %w", err)
+	}
+	sources, err := provenance(data, validated, extraction)
+	if err != nil {
+		return nil, report, err
+	}
+
+	return &Profile{
+		Data:       data,
+		Metadata:   e.metadata(interviewObj, userText, report),
+		Provenance: sources,
+		Validation: newValidationResult(report.ValidationError),
+	}, report, nil
+}
+
+// metadata собирает _metadata профиля: исходное интервью и сведения об обработке
+func (e *Extractor) metadata(interviewObj *Interview, userText string, report *Report) map[string]interface{} {
+	cfg := e.cfg
+	extraction := report.Extraction
+
+	metadata := map[string]interface{}{
+		"source_interview": interviewObj.GetInterviewMetadata(),
+		"processing_info": map[string]interface{}{
+			"schema_version":    "1.0",
+			"extraction_method": e.textBuilder.Name(),
+			"text_length":       len(userText),
+			"provider":          e.extractionProvider.Name(),
+			"structured_output": cfg.StructuredOutput,
+			"cache":             e.cached,
+			"extraction_mode":   cfg.Extraction.Mode,
+			"chunking": map[string]interface{}{
+				"max_tokens": cfg.Chunking.MaxTokens,
+				"chunks":     extraction.Chunks,
+				"conflicts":  extraction.Conflicts,
+			},
+			"json_repairs": map[string]interface{}{
+				"extraction": extraction.Repairs,
+				"validation": report.ValidationRepairs,
+			},
+			"stages": map[string]interface{}{
+				"extraction": cfg.Stages.Extraction,
+				"validation": cfg.Stages.Validation,
+			},
+			"usage": report.Usage,
+		},
+	}
+	if report.Resumed {
+		// Токены этапа 1 учтены в прерванном запуске
+		metadata["resumed_from"] = StageValidating
+	}
+	if len(extraction.Failed) > 0 {
+		// Разделы из неудавшихся запросов остались пустыми
+		metadata["failed_extractions"] = extraction.Failed
+	}
+	if len(report.RepairAttempts) > 0 {
+		metadata["repair_attempts"] = report.RepairAttempts
+	}
+
+	return metadata
+}
+
+func (e *Extractor) prompt(stage, prompt string) {
+	if e.hooks.OnPrompt != nil {
+		e.hooks.OnPrompt(stage, prompt)
+	}
+}
+
+func (e *Extractor) result(stage, json string) {
+	if e.hooks.OnResult != nil {
+		e.hooks.OnResult(stage, json)
+	}
+}
+
+// stageContext включает потоковый ответ по конфигурации. От hooks он не
+// зависит: stream меняет тело запроса, а по нему ищутся ответы в кэше и кассете.
+func (e *Extractor) stageContext(ctx context.Context, stage string) context.Context {
+	if !e.cfg.Stream {
+		return ctx
+	}
+	return api.WithProgress(ctx, func(p api.Progress) {
+		if e.hooks.OnProgress != nil {
+			e.hooks.OnProgress(stage, p)
+		}
+	})
+}
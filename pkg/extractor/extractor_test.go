@@ -0,0 +1,189 @@
+package extractor_test
+
+import (
+	"context"
+	"flag"
+	"net"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"profile-extractor/internal/api"
+	"profile-extractor/internal/fakellm"
+	"profile-extractor/pkg/extractor"
+)
+
+// Кассета записана с ответами internal/fakellm. Перезаписать ее после
+// изменения промптов или словаря:
+//
+//	go test ./pkg/extractor -run TestExtractReplaysCassette -record
+var record = flag.Bool("record", false, "перезаписать кассету ответами internal/fakellm")
+
+const (
+	cassettePath   = "../../testdata/cassettes/interview.json"
+	interviewPath  = "../../input/interview.json"
+	dictionaryPath = "../../config/dictionary.yaml"
+	// fakeLLMAddr входит в URL запросов, а значит и в ключи кассеты
+	fakeLLMAddr = "127.0.0.1:8090"
+)
+
+// TestExtractReplaysCassette проходит весь путь интервью → профиль без сети
+// и ключей: ответы провайдера берутся из кассеты testdata/cassettes.
+func TestExtractReplaysCassette(t *testing.T) {
+	fields := loadSchema(t)
+	interviewObj := loadInterview(t)
+
+	mode := api.CassetteReplay
+	if *record {
+		if err := os.Remove(cassettePath); err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		startFakeLLM(t, fields)
+		mode = api.CassetteRecord
+	}
+
+	// Поток включается конфигурацией, а не наличием hook: кассета, записанная
+	// без вывода прогресса, воспроизводится и с ним
+	variants := []struct {
+		name  string
+		hooks extractor.Hooks
+	}{
+		{"quiet", extractor.Hooks{}},
+		{"progress", extractor.Hooks{OnProgress: func(string, extractor.Progress) {}}},
+	}
+	if *record {
+		variants = variants[:1]
+	}
+
+	for _, variant := range variants {
+		t.Run(variant.name, func(t *testing.T) {
+			e, err := extractor.New(
+				extractor.WithConfig(testConfig(mode)),
+				extractor.WithSchema(fields),
+				extractor.WithHooks(variant.hooks),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			profile, report, err := e.Extract(context.Background(), interviewObj)
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			checkProfile(t, profile, report, interviewObj.InterviewID)
+		})
+	}
+}
+
+func checkProfile(t *testing.T, profile *extractor.Profile, report *extractor.Report, interviewID string) {
+	t.Helper()
+
+	if report.ValidationError != nil {
+		t.Errorf("validation error: %v", report.ValidationError)
+	}
+	if profile.Validation == nil || !profile.Validation.Valid {
+		t.Errorf("profile validation = %+v, want valid", profile.Validation)
+	}
+	if got := profile.InterviewID(); got != interviewID {
+		t.Errorf("InterviewID() = %q, want %q", got, interviewID)
+	}
+	if len(profile.Data) == 0 {
+		t.Fatal("profile has no fields")
+	}
+
+	// Тип элементов array<string> соблюден
+	skills, ok := profile.GetArray("career.skills")
+	if !ok {
+		t.Fatal("career.skills is missing")
+	}
+	for i, skill := range skills {
+		if _, ok := skill.(string); !ok {
+			t.Errorf("career.skills[%d] = %v, want string", i, skill)
+		}
+	}
+
+	// Поля получают источник: запросы извлечения или этап валидации
+	if len(profile.Provenance) == 0 {
+		t.Error("profile has no provenance")
+	}
+
+	for _, stage := range []string{"extraction", "validation"} {
+		usage := report.Usage.Stages[stage]
+		if usage == nil || usage.Calls == 0 {
+			t.Errorf("no %s calls in usage report", stage)
+		}
+	}
+
+	data, err := profile.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := extractor.ParseProfile(data)
+	if err != nil {
+		t.Fatalf("ParseProfile: %v", err)
+	}
+	if parsed.InterviewID() != interviewID {
+		t.Errorf("parsed InterviewID() = %q, want %q", parsed.InterviewID(), interviewID)
+	}
+}
+
+// testConfig — конфигурация без файла и окружения, чтобы тело запросов
+// и ключи кассеты не зависели от .env
+func testConfig(mode string) *extractor.Config {
+	cfg := extractor.DefaultConfig()
+	cfg.Provider = api.ProviderConfig{
+		Type:    api.ProviderLocal,
+		BaseURL: "http://" + fakeLLMAddr + "/v1",
+		Model:   "fake-model",
+	}
+	cfg.Stages.Extraction.BaseURL, cfg.Stages.Extraction.Model = "", ""
+	cfg.Stages.Validation.BaseURL, cfg.Stages.Validation.Model = "", ""
+	cfg.ResolveStages()
+	cfg.Retry.MaxAttempts = 1
+	cfg.Cache.Enabled = false
+	cfg.Cassette = api.CassetteConfig{Mode: mode, Path: cassettePath}
+	return cfg
+}
+
+// startFakeLLM запускает internal/fakellm на адресе, записанном в кассете
+func startFakeLLM(t *testing.T, fields extractor.Schema) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", fakeLLMAddr)
+	if err != nil {
+		t.Fatalf("listen %s: %v", fakeLLMAddr, err)
+	}
+	server := httptest.NewUnstartedServer(fakellm.New(fields, fakellm.Options{Seed: 1}))
+	server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+}
+
+func loadSchema(t *testing.T) extractor.Schema {
+	t.Helper()
+
+	data, err := os.ReadFile(dictionaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := extractor.ParseSchema(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fields
+}
+
+func loadInterview(t *testing.T) *extractor.Interview {
+	t.Helper()
+
+	data, err := os.ReadFile(interviewPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	interviewObj, err := extractor.ParseInterview(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return interviewObj
+}
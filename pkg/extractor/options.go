@@ -0,0 +1,128 @@
+package extractor
+
+import (
+	"log"
+
+	"profile-extractor/internal/prompts"
+	"profile-extractor/internal/validator"
+)
+
+// Option настраивает Extractor
+type Option func(*Extractor)
+
+// WithConfig задает настройки провайдера, этапов, деления интервью и повторов.
+// Без него используется DefaultConfig.
+func WithConfig(cfg *Config) Option {
+	return func(e *Extractor) { e.cfg = cfg }
+}
+
+// WithSchema задает словарь полей профиля. Обязательная опция.
+func WithSchema(fields Schema) Option {
+	return func(e *Extractor) { e.schema = fields }
+}
+
+// WithProvider использует provider на обоих этапах вместо провайдеров из
+// конфигурации. Повторы, кэш и лимиты конфигурации к нему не применяются.
+func WithProvider(provider Provider) Option {
+	return WithStageProviders(provider, provider)
+}
+
+// WithStageProviders задает отдельных провайдеров для извлечения и валидации
+func WithStageProviders(extraction, validation Provider) Option {
+	return func(e *Extractor) {
+		e.extractionProvider = extraction
+		e.validationProvider = validation
+	}
+}
+
+// WithPrompts заменяет промпты этапов
+func WithPrompts(p Prompts) Option {
+	return func(e *Extractor) { e.prompts = p }
+}
+
+// WithValidator заменяет проверку профиля по схеме
+func WithValidator(v Validator) Option {
+	return func(e *Extractor) { e.validate = v }
+}
+
+// WithTextBuilder задает способ построения текста вместо extraction.text_method
+func WithTextBuilder(b TextBuilder) Option {
+	return func(e *Extractor) { e.textBuilder = b }
+}
+
+// WithHooks подключает обратные вызовы
+func WithHooks(h Hooks) Option {
+	return func(e *Extractor) { e.hooks = h }
+}
+
+// WithLogger направляет в logger сообщения о ходе обработки: этапы, повторы
+// запросов, исправления JSON. Без него сообщения не пишутся.
+func WithLogger(logger *log.Logger) Option {
+	return func(e *Extractor) { e.logger = logger }
+}
+
+// WithoutCache отключает дисковый кэш ответов, даже если он включен в конфигурации
+func WithoutCache() Option {
+	return func(e *Extractor) { e.noCache = true }
+}
+
+// Prompts строит промпты этапов
+type Prompts interface {
+	// Extraction — промпт извлечения из части part из total (total == 1 — интервью целиком)
+	Extraction(fields Schema, text string, part, total int) string
+	// Validation — промпт проверки и очистки извлеченного профиля
+	Validation(fields Schema, profileJSON string) string
+	// Repair — промпт исправления профиля по ошибкам валидатора
+	Repair(fields Schema, profileJSON string, validationErrors []string) string
+}
+
+// DefaultPrompts — промпты из internal/prompts
+type DefaultPrompts struct{}
+
+func (DefaultPrompts) Extraction(fields Schema, text string, part, total int) string {
+	if total <= 1 {
+		return prompts.GenerateExtractionPrompt(fields, text)
+	}
+	return prompts.GenerateChunkExtractionPrompt(fields, text, part, total)
+}
+
+func (DefaultPrompts) Validation(fields Schema, profileJSON string) string {
+	return prompts.GenerateValidationPrompt(fields, profileJSON)
+}
+
+func (DefaultPrompts) Repair(fields Schema, profileJSON string, validationErrors []string) string {
+	return prompts.GenerateRepairPrompt(fields, profileJSON, validationErrors)
+}
+
+// Validator проверяет профиль по схеме. Нарушения схемы возвращаются как
+// ValidationErrors — только их цикл исправления отправляет модели.
+type Validator func(profileJSON string, fields Schema) error
+
+// DefaultValidator — проверка типов из internal/validator
+func DefaultValidator(profileJSON string, fields Schema) error {
+	return validator.ValidateProfileJSON(profileJSON, fields)
+}
+
+// Stage — этап обработки интервью
+type Stage string
+
+const (
+	StageExtracting Stage = "extracting"
+	StageValidating Stage = "validating"
+	StageRepairing  Stage = "repairing"
+)
+
+// Hooks — обратные вызовы для наблюдения за обработкой. Вызываются из горутин
+// запросов, поэтому должны быть безопасны при параллельном вызове. Любое поле
+// может быть nil.
+type Hooks struct {
+	// OnStage — переход к этапу; для StageValidating передается результат извлечения
+	OnStage func(stage Stage, extraction *Extraction)
+	// OnPrompt — промпт перед отправкой модели
+	OnPrompt func(stage, prompt string)
+	// OnResult — JSON ответа этапа после восстановления
+	OnResult func(stage, json string)
+	// OnProgress — прогресс потокового ответа. Ответ запрашивается потоком,
+	// если stream включен в конфигурации, даже без этого hook.
+	OnProgress func(stage string, progress Progress)
+}
@@ -0,0 +1,303 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Profile — извлеченный профиль: дерево полей по словарю, метаданные,
+// происхождение полей и результат проверки по схеме. Числа хранятся как
+// json.Number, поэтому запись в JSON и обратное чтение не меняют значений.
+type Profile struct {
+	// Data — поля профиля по словарю
+	Data map[string]interface{}
+	// Metadata — остальные поля _metadata: исходное интервью и сведения об обработке
+	Metadata map[string]interface{}
+	// Provenance — происхождение непустых полей по пути через точку
+	Provenance map[string]FieldSource
+	// Validation — результат финальной проверки по схеме
+	Validation *ValidationResult
+}
+
+// FieldSource — откуда поле получило значение
+type FieldSource struct {
+	// Stage — этап, после которого значение не менялось: извлечение, валидация или исправление
+	Stage Stage `json:"stage"`
+	// Requests — запросы извлечения, вернувшие непустое значение поля
+	Requests []string `json:"requests,omitempty"`
+}
+
+// ValidationResult — результат проверки профиля по схеме
+type ValidationResult struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+func newValidationResult(err error) *ValidationResult {
+	if err == nil {
+		return &ValidationResult{Valid: true}
+	}
+	var validationErrs ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return &ValidationResult{Errors: validationErrs.Messages()}
+	}
+	return &ValidationResult{Errors: []string{err.Error()}}
+}
+
+// ParseProfile разбирает профиль в формате profile_<id>.json
+func ParseProfile(data []byte) (*Profile, error) {
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("error parsing profile: %w", err)
+	}
+	return &p, nil
+}
+
+// Поля _metadata, которые хранятся в Profile отдельно
+const (
+	provenanceKey = "provenance"
+	validationKey = "validation"
+)
+
+// MarshalJSON записывает профиль в формате profile_<id>.json: поля профиля и _metadata.
+// Получатель — значение, чтобы формат сохранялся и для Profile внутри структур и map.
+func (p Profile) MarshalJSON() ([]byte, error) {
+	metadata := make(map[string]interface{}, len(p.Metadata)+2)
+	for key, value := range p.Metadata {
+		metadata[key] = value
+	}
+	if len(p.Provenance) > 0 {
+		metadata[provenanceKey] = p.Provenance
+	}
+	if p.Validation != nil {
+		metadata[validationKey] = p.Validation
+	}
+
+	out := make(map[string]interface{}, len(p.Data)+1)
+	for key, value := range p.Data {
+		out[key] = value
+	}
+	if len(metadata) > 0 {
+		out["_metadata"] = metadata
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON читает профиль, записанный MarshalJSON
+func (p *Profile) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var metadata map[string]json.RawMessage
+	if rawMetadata, ok := raw["_metadata"]; ok {
+		if err := json.Unmarshal(rawMetadata, &metadata); err != nil {
+			return fmt.Errorf("_metadata: %w", err)
+		}
+		delete(raw, "_metadata")
+	}
+
+	*p = Profile{}
+	if rawProvenance, ok := metadata[provenanceKey]; ok {
+		if err := json.Unmarshal(rawProvenance, &p.Provenance); err != nil {
+			return fmt.Errorf("_metadata.%s: %w", provenanceKey, err)
+		}
+		delete(metadata, provenanceKey)
+	}
+	if rawValidation, ok := metadata[validationKey]; ok {
+		if err := json.Unmarshal(rawValidation, &p.Validation); err != nil {
+			return fmt.Errorf("_metadata.%s: %w", validationKey, err)
+		}
+		delete(metadata, validationKey)
+	}
+
+	var err error
+	if p.Data, err = decodeFields(raw); err != nil {
+		return err
+	}
+	if metadata != nil {
+		if p.Metadata, err = decodeFields(metadata); err != nil {
+			return fmt.Errorf("_metadata: %w", err)
+		}
+	}
+	return nil
+}
+
+// JSON возвращает профиль с отступами, как он сохраняется в файл
+func (p *Profile) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling profile: %w", err)
+	}
+	return data, nil
+}
+
+// InterviewID возвращает ID исходного интервью из _metadata
+func (p *Profile) InterviewID() string {
+	source, _ := p.Metadata["source_interview"].(map[string]interface{})
+	id, _ := source["interview_id"].(string)
+	return id
+}
+
+// Get возвращает значение поля по пути через точку, например "location.current.city"
+func (p *Profile) Get(path string) (interface{}, bool) {
+	var value interface{} = p.Data
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, value != nil
+}
+
+// GetString возвращает строковое поле; ok == false, если поля нет или это не строка
+func (p *Profile) GetString(path string) (string, bool) {
+	value, _ := p.Get(path)
+	s, ok := value.(string)
+	return s, ok
+}
+
+// GetInt возвращает целочисленное поле
+func (p *Profile) GetInt(path string) (int64, bool) {
+	value, _ := p.Get(path)
+	switch v := value.(type) {
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case float64:
+		return int64(v), v == float64(int64(v))
+	}
+	return 0, false
+}
+
+// GetFloat возвращает числовое поле
+func (p *Profile) GetFloat(path string) (float64, bool) {
+	value, _ := p.Get(path)
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// GetBool возвращает логическое поле
+func (p *Profile) GetBool(path string) (bool, bool) {
+	value, _ := p.Get(path)
+	b, ok := value.(bool)
+	return b, ok
+}
+
+// GetArray возвращает поле-массив, например "career.skills"
+func (p *Profile) GetArray(path string) ([]interface{}, bool) {
+	value, _ := p.Get(path)
+	array, ok := value.([]interface{})
+	return array, ok
+}
+
+// GetObject возвращает вложенный объект
+func (p *Profile) GetObject(path string) (map[string]interface{}, bool) {
+	value, _ := p.Get(path)
+	object, ok := value.(map[string]interface{})
+	return object, ok
+}
+
+// decodeObject разбирает JSON объект, сохраняя числа как json.Number
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+	if object == nil {
+		return nil, errors.New("expected JSON object, got null")
+	}
+	return object, nil
+}
+
+func decodeFields(raw map[string]json.RawMessage) (map[string]interface{}, error) {
+	fields := make(map[string]interface{}, len(raw))
+	for key, value := range raw {
+		decoder := json.NewDecoder(bytes.NewReader(value))
+		decoder.UseNumber()
+		var decoded interface{}
+		if err := decoder.Decode(&decoded); err != nil {
+			return nil, fmt.Errorf("field %s: %w", key, err)
+		}
+		fields[key] = decoded
+	}
+	return fields, nil
+}
+
+// leafValues возвращает непустые значения профиля по путям через точку.
+// Массивы считаются значениями и не разворачиваются.
+func leafValues(object map[string]interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	var walk func(prefix string, object map[string]interface{})
+	walk = func(prefix string, object map[string]interface{}) {
+		for key, value := range object {
+			path := prefix + key
+			switch v := value.(type) {
+			case nil:
+			case map[string]interface{}:
+				walk(path+".", v)
+			case string:
+				if strings.TrimSpace(v) != "" {
+					values[path] = v
+				}
+			case []interface{}:
+				if len(v) > 0 {
+					values[path] = v
+				}
+			default:
+				values[path] = v
+			}
+		}
+	}
+	walk("", object)
+	return values
+}
+
+// provenance определяет, на каком этапе поле финального профиля получило
+// значение: совпадающее с результатом извлечения относится к запросам
+// извлечения, измененное валидацией — к ней, остальное — к исправлению.
+func provenance(final, validated map[string]interface{}, extraction *Extraction) (map[string]FieldSource, error) {
+	extracted, err := decodeObject([]byte(extraction.JSON))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing extracted profile: %w", err)
+	}
+	extractedValues := leafValues(extracted)
+	validatedValues := leafValues(validated)
+
+	sources := make(map[string]FieldSource)
+	for path, value := range leafValues(final) {
+		switch {
+		case reflect.DeepEqual(value, extractedValues[path]):
+			sources[path] = FieldSource{Stage: StageExtracting, Requests: extraction.Sources[path]}
+		case reflect.DeepEqual(value, validatedValues[path]):
+			sources[path] = FieldSource{Stage: StageValidating}
+		default:
+			sources[path] = FieldSource{Stage: StageRepairing}
+		}
+	}
+	return sources, nil
+}
+
+// recordSources добавляет запрос label к источникам непустых полей частичного профиля
+func recordSources(sources map[string][]string, label string, partial map[string]interface{}) {
+	for path := range leafValues(partial) {
+		sources[path] = append(sources[path], label)
+	}
+}
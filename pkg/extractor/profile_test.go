@@ -0,0 +1,91 @@
+package extractor_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"profile-extractor/pkg/extractor"
+)
+
+func testProfile() extractor.Profile {
+	return extractor.Profile{
+		Data: map[string]interface{}{
+			"career": map[string]interface{}{
+				"experience_years": json.Number("0"),
+				"skills":           []interface{}{"Go", "SQL"},
+			},
+			"name": "Анна",
+		},
+		Metadata: map[string]interface{}{
+			"source_interview": map[string]interface{}{"interview_id": "int_001"},
+		},
+		Provenance: map[string]extractor.FieldSource{
+			"name": {Stage: extractor.StageExtracting, Requests: []string{"basic_info"}},
+		},
+		Validation: &extractor.ValidationResult{Valid: true},
+	}
+}
+
+// TestProfileMarshalValue проверяет, что Profile, записанный по значению
+// внутри структуры и map, сохраняет формат profile_<id>.json
+func TestProfileMarshalValue(t *testing.T) {
+	profile := testProfile()
+
+	type wrapper struct {
+		Profile  extractor.Profile            `json:"profile"`
+		Profiles map[string]extractor.Profile `json:"profiles"`
+	}
+	data, err := json.Marshal(wrapper{
+		Profile:  profile,
+		Profiles: map[string]extractor.Profile{"int_001": profile},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var raw struct {
+		Profile  map[string]json.RawMessage            `json:"profile"`
+		Profiles map[string]map[string]json.RawMessage `json:"profiles"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, fields := range []map[string]json.RawMessage{raw.Profile, raw.Profiles["int_001"]} {
+		if _, ok := fields["_metadata"]; !ok {
+			t.Errorf("marshaled profile has no _metadata: %s", data)
+		}
+		if _, ok := fields["Data"]; ok {
+			t.Errorf("marshaled profile uses default struct encoding: %s", data)
+		}
+	}
+
+	var decoded wrapper
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string]extractor.Profile{"field": decoded.Profile, "map": decoded.Profiles["int_001"]} {
+		if !reflect.DeepEqual(got, profile) {
+			t.Errorf("%s round trip = %+v, want %+v", name, got, profile)
+		}
+		if got.InterviewID() != "int_001" {
+			t.Errorf("%s InterviewID() = %q, want int_001", name, got.InterviewID())
+		}
+	}
+}
+
+func TestProfileMarshalPointerAndValueMatch(t *testing.T) {
+	profile := testProfile()
+
+	byValue, err := json.Marshal(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPointer, err := json.Marshal(&profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(byValue) != string(byPointer) {
+		t.Errorf("value = %s, pointer = %s", byValue, byPointer)
+	}
+}
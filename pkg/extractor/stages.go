@@ -0,0 +1,381 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"profile-extractor/internal/api"
+	"profile-extractor/internal/config"
+	"profile-extractor/internal/merge"
+	"profile-extractor/internal/schema"
+	"profile-extractor/internal/validator"
+)
+
+// newProviders создает провайдеров этапов по конфигурации
+func (e *Extractor) newProviders() error {
+	// Копия: транспорт кассеты и ключ replay не должны попасть в конфигурацию вызывающего
+	cfgCopy := *e.cfg
+	cfg := &cfgCopy
+
+	// Кассета подменяет сеть: в режиме replay ключ и доступ к провайдеру не нужны
+	if cfg.Cassette.Mode != "" {
+		cassette, err := api.NewCassette(cfg.Cassette)
+		if err != nil {
+			return fmt.Errorf("error opening cassette: %w", err)
+		}
+		cfg.Provider.Transport = cassette
+		if cfg.Cassette.Mode == api.CassetteReplay && cfg.Provider.APIKey == "" {
+			cfg.Provider.APIKey = "replay"
+		}
+		e.logger.Printf("Cassette %s mode: %s", cfg.Cassette.Mode, cfg.Cassette.Path)
+	}
+
+	// С кассетой кэш не используется, иначе запись пропустит запросы,
+	// на которые ответил кэш
+	var cache *api.ResponseCache
+	if cfg.Cache.Enabled && !e.noCache && cfg.Cassette.Mode == "" {
+		var err error
+		cache, err = api.NewResponseCache(cfg.Cache)
+		if err != nil {
+			return fmt.Errorf("error opening response cache: %w", err)
+		}
+	}
+
+	limiter := api.NewRateLimiter(cfg.RateLimit)
+
+	extractionProvider, err := newStageProvider(cfg, cfg.Stages.Extraction, cache, limiter)
+	if err != nil {
+		return fmt.Errorf("error creating extraction provider: %w", err)
+	}
+	validationProvider, err := newStageProvider(cfg, cfg.Stages.Validation, cache, limiter)
+	if err != nil {
+		return fmt.Errorf("error creating validation provider: %w", err)
+	}
+
+	e.logger.Printf("Using LLM provider: %s (extraction: %s, validation: %s)",
+		extractionProvider.Name(), cfg.Stages.Extraction.Model, cfg.Stages.Validation.Model)
+
+	e.extractionProvider = extractionProvider
+	e.validationProvider = validationProvider
+	e.cached = cache != nil
+	return nil
+}
+
+// newStageProvider создает провайдера для этапа с политикой повторов.
+// Кэш проверяется до повторов, чтобы сохраненный ответ не ждал backoff,
+// а лимитер стоит под повторами, чтобы каждая попытка расходовала бюджет.
+func newStageProvider(cfg *config.Config, stage config.StageConfig, cache *api.ResponseCache, limiter *api.RateLimiter) (api.Provider, error) {
+	provider, err := api.NewProvider(cfg.StageProvider(stage))
+	if err != nil {
+		return nil, err
+	}
+	provider = api.WithRateLimit(provider, limiter)
+	return api.WithCache(api.WithRetry(provider, cfg.Retry), cache, stage.BaseURL), nil
+}
+
+// extract выполняет этап 1: интервью делится на части (по блокам и/или по
+// бюджету токенов), из каждой извлекается частичный профиль, затем они объединяются
+func (e *Extractor) extract(ctx context.Context, interviewObj *Interview, usage *UsageReport) (*Extraction, error) {
+	cfg := e.cfg
+
+	e.logger.Println("\nStep 1: Extracting profile data from interview...")
+	jobs := e.planExtraction(interviewObj)
+	if len(jobs) > 1 {
+		e.logger.Printf("Interview split into %d extraction requests (mode: %s)", len(jobs), cfg.Extraction.Mode)
+	}
+	for i := range jobs {
+		jobs[i].prompt = e.prompts.Extraction(jobs[i].fields, jobs[i].text, i+1, len(jobs))
+		e.prompt("extraction "+jobs[i].label, jobs[i].prompt)
+	}
+
+	extraction, err := e.extractJobs(e.stageContext(ctx, "extraction"), jobs, usage)
+	if err != nil {
+		return nil, err
+	}
+	extraction.Chunks = len(jobs)
+	return extraction, nil
+}
+
+// extractionJob — один запрос извлечения: часть интервью и поля схемы для нее
+type extractionJob struct {
+	label  string
+	text   string
+	fields Schema
+	prompt string
+}
+
+// planExtraction делит интервью на запросы извлечения. В режиме blocks каждый
+// блок получает только свои разделы схемы; любая часть, которая больше бюджета
+// chunking.max_tokens, дополнительно делится по парам вопрос-ответ.
+func (e *Extractor) planExtraction(interviewObj *Interview) []extractionJob {
+	cfg := e.cfg
+	var jobs []extractionJob
+
+	addChunks := func(label string, part *Interview, fields Schema) {
+		chunks := part.SplitByTokens(cfg.Chunking.MaxTokens, api.EstimateTokens)
+		for i, chunk := range chunks {
+			chunkLabel := label
+			if len(chunks) > 1 {
+				chunkLabel = fmt.Sprintf("%s part %d/%d", label, i+1, len(chunks))
+			}
+			jobs = append(jobs, extractionJob{label: chunkLabel, text: e.textBuilder.Build(chunk), fields: fields})
+		}
+	}
+
+	if cfg.Extraction.Mode != config.ExtractionBlocks {
+		addChunks("interview", interviewObj, e.schema)
+		return jobs
+	}
+
+	for _, block := range interviewObj.SplitByBlock() {
+		blockName := block.Blocks[0].BlockName
+		sections, mapped := cfg.Extraction.SectionsForBlock(blockName)
+		if !mapped {
+			e.logger.Printf("Block %s has no entry in extraction.block_sections, using full schema", blockName)
+		}
+
+		fields := schema.FilterSections(e.schema, sections)
+		if len(fields) == 0 {
+			e.logger.Printf("Block %s maps to no schema fields, skipping", blockName)
+			continue
+		}
+		addChunks("block "+blockName, block, fields)
+	}
+
+	return jobs
+}
+
+// Extraction — результат этапа 1: профиль, собранный из частей интервью.
+// Сериализуется в JSON, чтобы обработку можно было продолжить через ResumeFrom.
+type Extraction struct {
+	JSON string `json:"profile_json"`
+	// Chunks — число запросов извлечения
+	Chunks    int        `json:"chunks"`
+	Repairs   []string   `json:"repairs,omitempty"`
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+	// Failed — запросы, которые не удалось выполнить; их разделы остались пустыми
+	Failed []FailedExtraction `json:"failed,omitempty"`
+	// Sources — запросы, вернувшие непустое значение, по пути поля
+	Sources map[string][]string `json:"sources,omitempty"`
+}
+
+// FailedExtraction — запись в _metadata о неудавшемся запросе извлечения
+type FailedExtraction struct {
+	Label    string   `json:"label"`
+	Sections []string `json:"sections"`
+	Error    string   `json:"error"`
+}
+
+// jobResult — результат одного запроса, собирается в порядке запросов
+type jobResult struct {
+	extraction *api.ExtractResult
+	partial    map[string]interface{}
+	err        error
+}
+
+// extractJobs выполняет запросы извлечения параллельно, не больше
+// extraction.workers одновременно (map), и детерминированно объединяет частичные
+// профили в порядке запросов (reduce). Неудавшиеся запросы не прерывают
+// остальные: их разделы остаются пустыми и перечисляются в Failed. Ошибка
+// возвращается, только если не удалось ничего или контекст отменен.
+func (e *Extractor) extractJobs(ctx context.Context, jobs []extractionJob, usage *UsageReport) (*Extraction, error) {
+	if len(jobs) == 0 {
+		return nil, errors.New("interview has no answers to extract from")
+	}
+	workers := e.cfg.Extraction.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]jobResult, len(jobs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers && w < len(jobs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = e.runJob(ctx, jobs[i], usage)
+			}
+		}()
+	}
+
+	for i := range jobs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(jobs) == 1 {
+		if results[0].err != nil {
+			return nil, results[0].err
+		}
+		result := &Extraction{
+			JSON:    results[0].extraction.JSON,
+			Repairs: results[0].extraction.Repairs,
+			Sources: make(map[string][]string),
+		}
+		recordSources(result.Sources, jobs[0].label, results[0].partial)
+		return result, nil
+	}
+
+	result := &Extraction{Sources: make(map[string][]string)}
+	partials := make([]map[string]interface{}, 0, len(jobs))
+	for i, job := range jobs {
+		jobRes := results[i]
+		if jobRes.err != nil {
+			e.logger.Printf("Extraction of %s failed: %v", job.label, jobRes.err)
+			result.Failed = append(result.Failed, FailedExtraction{
+				Label:    job.label,
+				Sections: topLevelSections(job.fields),
+				Error:    jobRes.err.Error(),
+			})
+			continue
+		}
+
+		for _, repair := range jobRes.extraction.Repairs {
+			result.Repairs = append(result.Repairs, fmt.Sprintf("%s: %s", job.label, repair))
+		}
+		recordSources(result.Sources, job.label, jobRes.partial)
+		partials = append(partials, jobRes.partial)
+	}
+
+	if len(partials) == 0 {
+		return nil, fmt.Errorf("all %d extraction requests failed, first error: %w", len(jobs), results[0].err)
+	}
+
+	merged, conflicts := merge.Profiles(partials)
+	if len(conflicts) > 0 {
+		e.logger.Printf("Merged %d partial profiles, resolved %d conflicting field(s)", len(partials), len(conflicts))
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling merged profile: %w", err)
+	}
+	result.JSON = string(data)
+	result.Conflicts = conflicts
+
+	return result, nil
+}
+
+// runJob выполняет один запрос извлечения
+func (e *Extractor) runJob(ctx context.Context, job extractionJob, usage *UsageReport) jobResult {
+	// Схема ответа строится по полям запроса, чтобы модель не заполняла чужие разделы
+	var format *api.ResponseFormat
+	if e.cfg.StructuredOutput {
+		format = api.JSONSchemaFormat("profile", schema.ToJSONSchema(job.fields))
+	}
+
+	extraction, err := api.ExtractProfile(ctx, e.extractionProvider, e.cfg.Stages.Extraction.ModelParams, job.prompt, format)
+	if err != nil {
+		return jobResult{err: err}
+	}
+	e.logRepairs("extraction "+job.label, extraction)
+	usage.Record("extraction", extraction)
+
+	var partial map[string]interface{}
+	if err := json.Unmarshal([]byte(extraction.JSON), &partial); err != nil {
+		return jobResult{err: fmt.Errorf("model returned non-object JSON: %w", err)}
+	}
+	return jobResult{extraction: extraction, partial: partial}
+}
+
+// topLevelSections возвращает разделы схемы (первую часть имени поля)
+func topLevelSections(fields Schema) []string {
+	seen := make(map[string]bool)
+	var sections []string
+	for key := range fields {
+		section := strings.SplitN(key, ".", 2)[0]
+		if !seen[section] {
+			seen[section] = true
+			sections = append(sections, section)
+		}
+	}
+	sort.Strings(sections)
+	return sections
+}
+
+// RepairAttempt — запись об одной попытке исправления профиля по ошибкам валидатора
+type RepairAttempt struct {
+	Attempt int      `json:"attempt"`
+	Errors  []string `json:"errors"`
+	Fixed   bool     `json:"fixed"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// repairProfile отправляет модели ошибки валидатора и текущий JSON, пока профиль
+// не пройдет проверку или не закончатся попытки repair.max_attempts.
+// Возвращает последний профиль и историю попыток.
+func (e *Extractor) repairProfile(ctx context.Context, run *extractRun, profileJSON string,
+	usage *UsageReport) (string, []RepairAttempt, error) {
+	maxAttempts := e.cfg.Repair.MaxAttempts
+	params := e.cfg.Stages.Validation.ModelParams
+	ctx = e.stageContext(ctx, "repair")
+	var history []RepairAttempt
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		validationErrs := e.repairableErrors(profileJSON)
+		if len(validationErrs) == 0 {
+			break
+		}
+		if attempt == 1 {
+			e.enter(run, StageRepairing, nil)
+		}
+
+		e.logger.Printf("Repair attempt %d/%d: %d validation error(s)", attempt, maxAttempts, len(validationErrs))
+		record := RepairAttempt{Attempt: attempt, Errors: validationErrs.Messages()}
+
+		prompt := e.prompts.Repair(e.schema, profileJSON, record.Errors)
+		e.prompt("repair", prompt)
+		result, err := api.ExtractProfile(ctx, e.validationProvider, params, prompt, e.responseFormat)
+		if err != nil {
+			if ctx.Err() != nil {
+				return profileJSON, history, err
+			}
+			// Неудачная попытка не должна терять уже извлеченный профиль
+			record.Error = err.Error()
+			history = append(history, record)
+			continue
+		}
+
+		e.logRepairs("repair", result)
+		usage.Record("repair", result)
+		profileJSON = result.JSON
+		e.result("repair", profileJSON)
+		record.Fixed = len(e.repairableErrors(profileJSON)) == 0
+		history = append(history, record)
+	}
+
+	return profileJSON, history, nil
+}
+
+// repairableErrors возвращает нарушения схемы, которые отправляются модели.
+// Пустые обязательные поля остаются только в итоговой проверке (Report.ValidationError).
+func (e *Extractor) repairableErrors(profileJSON string) validator.ValidationErrors {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(e.validate(profileJSON, e.schema), &validationErrs) {
+		return nil
+	}
+	return validationErrs.Repairable()
+}
+
+// logRepairs сообщает, какие исправления понадобились ответу модели
+func (e *Extractor) logRepairs(stage string, result *api.ExtractResult) {
+	if result.Continuations > 0 {
+		e.logger.Printf("Response of %s stage was truncated, requested %d continuation(s)", stage, result.Continuations)
+	}
+	if len(result.Repairs) > 0 {
+		e.logger.Printf("Repaired JSON of %s stage: %s", stage, strings.Join(result.Repairs, "; "))
+	}
+}
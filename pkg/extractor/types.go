@@ -0,0 +1,82 @@
+package extractor
+
+import (
+	"fmt"
+
+	"profile-extractor/internal/api"
+	"profile-extractor/internal/config"
+	"profile-extractor/internal/interview"
+	"profile-extractor/internal/merge"
+	"profile-extractor/internal/schema"
+	"profile-extractor/internal/validator"
+)
+
+// Типы внутренних пакетов, доступные сервисам вне модуля
+type (
+	// Interview — интервью в формате input/*.json
+	Interview         = interview.Interview
+	Block             = interview.Block
+	QuestionAndAnswer = interview.QuestionAndAnswer
+	// TextBuilder строит из интервью текст, который получает модель
+	TextBuilder = interview.TextBuilder
+
+	// Schema — словарь полей профиля (config/dictionary.yaml)
+	Schema      = map[string]schema.SchemaField
+	SchemaField = schema.SchemaField
+
+	// Config — настройки провайдера и этапов (config/config.yaml)
+	Config = config.Config
+
+	// Provider — LLM провайдер; свою реализацию можно передать через WithProvider
+	Provider     = api.Provider
+	ChatRequest  = api.ChatRequest
+	ChatResponse = api.ChatResponse
+	Message      = api.Message
+	Capabilities = api.Capabilities
+	Usage        = api.Usage
+	// UsageReport — токены и стоимость по этапам
+	UsageReport = api.UsageReport
+	// Progress — состояние потокового ответа модели
+	Progress = api.Progress
+
+	// Conflict — поле, для которого части интервью дали разные значения
+	Conflict = merge.Conflict
+	// ValidationErrors — нарушения схемы, найденные валидатором
+	ValidationErrors = validator.ValidationErrors
+	// RequiredError — пустое обязательное поле; в цикл исправления не отправляется
+	RequiredError = validator.RequiredError
+)
+
+// Функции реестра способов построения текста
+var (
+	RegisterTextBuilder = interview.RegisterTextBuilder
+	LookupTextBuilder   = interview.LookupTextBuilder
+	NewTextBuilder      = interview.NewTextBuilder
+	WithAnswerFilter    = interview.WithAnswerFilter
+)
+
+// ParseInterview разбирает JSON интервью
+func ParseInterview(data []byte) (*Interview, error) {
+	return interview.ParseInterviewJSON(data)
+}
+
+// ParseSchema разбирает словарь полей профиля в формате dictionary.yaml
+func ParseSchema(yamlContent []byte) (Schema, error) {
+	fields, err := schema.ParseYAMLSchema(yamlContent)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing schema: %w", err)
+	}
+	return fields, nil
+}
+
+// LoadConfig читает config.yaml и переопределения из окружения
+func LoadConfig(path string) (*Config, error) {
+	return config.Load(path)
+}
+
+// DefaultConfig возвращает конфигурацию по умолчанию без файла и окружения
+func DefaultConfig() *Config {
+	cfg := config.Default()
+	cfg.ResolveStages()
+	return cfg
+}
@@ -0,0 +1,11 @@
+// Package profile — типизированная структура профиля, сгенерированная из
+// config/dictionary.yaml. Сервисы, читающие profile_<id>.json, получают
+// ошибку компиляции вместо пустого значения при опечатке в пути поля.
+//
+//	p, err := profile.Decode(data)
+//	fmt.Println(p.Family.Childhood.Structure, p.Career.ExperienceYears)
+//
+// После изменения словаря структуру нужно перегенерировать: go generate ./pkg/profile
+package profile
+
+//go:generate go run ../.. schema -q -schema ../../config/dictionary.yaml -o profile_gen.go codegen
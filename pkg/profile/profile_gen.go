@@ -0,0 +1,337 @@
+// Code generated by profile-extractor schema codegen from ../../config/dictionary.yaml. DO NOT EDIT.
+
+package profile
+
+import "encoding/json"
+
+// Profile — профиль по словарю полей
+type Profile struct {
+	Accomplishments Accomplishments `json:"accomplishments"`
+	Achievements    Achievements    `json:"achievements"`
+	// возраст в годах на момент интервью
+	Age            *int                   `json:"age,omitempty"`
+	Aspirations    Aspirations            `json:"aspirations"`
+	Career         Career                 `json:"career"`
+	Challenges     Challenges             `json:"challenges"`
+	Character      Character              `json:"character"`
+	Contact        Contact                `json:"contact"`
+	Creative       Creative               `json:"creative"`
+	Education      Education              `json:"education"`
+	Failures       Failures               `json:"failures"`
+	Family         Family                 `json:"family"`
+	Future         Future                 `json:"future"`
+	Gender         *string                `json:"gender,omitempty"`
+	Health         Health                 `json:"health"`
+	Hobbies        Hobbies                `json:"hobbies"`
+	ID             *string                `json:"id,omitempty"`
+	Impact         Impact                 `json:"impact"`
+	Intellectual   Intellectual           `json:"intellectual"`
+	Interests      Interests              `json:"interests"`
+	Leisure        Leisure                `json:"leisure"`
+	Location       Location               `json:"location"`
+	Motivation     Motivation             `json:"motivation"`
+	Name           *string                `json:"name,omitempty"`
+	Obstacles      Obstacles              `json:"obstacles"`
+	PersonalGrowth PersonalGrowth         `json:"personal_growth"`
+	Personality    Personality            `json:"personality"`
+	Planning       Planning               `json:"planning"`
+	Profession     Profession             `json:"profession"`
+	Recognition    Recognition            `json:"recognition"`
+	Relationships  Relationships          `json:"relationships"`
+	Resilience     Resilience             `json:"resilience"`
+	Social         Social                 `json:"social"`
+	Success        Success                `json:"success"`
+	Tags           map[string]interface{} `json:"tags,omitempty"`
+	Values         Values                 `json:"values"`
+	Wellness       Wellness               `json:"wellness"`
+	Worldview      Worldview              `json:"worldview"`
+
+	// Metadata — _metadata: исходное интервью и сведения об обработке
+	Metadata map[string]interface{} `json:"_metadata,omitempty"`
+}
+
+// Accomplishments — раздел accomplishments
+type Accomplishments struct {
+	ProudMoments []interface{} `json:"proud_moments,omitempty"`
+}
+
+// Achievements — раздел achievements
+type Achievements struct {
+	Academic     []interface{} `json:"academic,omitempty"`
+	Creative     []interface{} `json:"creative,omitempty"`
+	Personal     []interface{} `json:"personal,omitempty"`
+	Professional []interface{} `json:"professional,omitempty"`
+}
+
+// Aspirations — раздел aspirations
+type Aspirations struct {
+	Legacy *string `json:"legacy,omitempty"`
+}
+
+// Career — раздел career
+type Career struct {
+	Achievements []interface{} `json:"achievements,omitempty"`
+	CurrentRole  *string       `json:"current_role,omitempty"`
+	// общий стаж работы в годах
+	ExperienceYears      *int             `json:"experience_years,omitempty"`
+	LeadershipExperience []interface{}    `json:"leadership_experience,omitempty"`
+	Path                 []CareerPathItem `json:"path,omitempty"`
+	Skills               []string         `json:"skills,omitempty"`
+	WorkValues           []interface{}    `json:"work_values,omitempty"`
+}
+
+// CareerPathItem — элемент массива career.path
+type CareerPathItem struct {
+	Company *string `json:"company,omitempty"`
+	From    *int    `json:"from,omitempty"`
+	Role    *string `json:"role,omitempty"`
+	To      *int    `json:"to,omitempty"`
+}
+
+// Challenges — раздел challenges
+type Challenges struct {
+	CopingStrategies  []interface{} `json:"coping_strategies,omitempty"`
+	LessonsLearned    []interface{} `json:"lessons_learned,omitempty"`
+	MajorDifficulties []interface{} `json:"major_difficulties,omitempty"`
+	SupportSystems    []interface{} `json:"support_systems,omitempty"`
+}
+
+// Character — раздел character
+type Character struct {
+	ValuesDemonstration []interface{} `json:"values_demonstration,omitempty"`
+}
+
+// Contact — раздел contact
+type Contact struct {
+	Email *string `json:"email,omitempty"`
+	Phone *string `json:"phone,omitempty"`
+}
+
+// Creative — раздел creative
+type Creative struct {
+	Projects []interface{} `json:"projects,omitempty"`
+	Skills   []interface{} `json:"skills,omitempty"`
+}
+
+// Education — раздел education
+type Education struct {
+	InfluentialTeachers []interface{}         `json:"influential_teachers,omitempty"`
+	KeyExperiences      []interface{}         `json:"key_experiences,omitempty"`
+	LearningStyle       *string               `json:"learning_style,omitempty"`
+	Levels              []EducationLevelsItem `json:"levels,omitempty"`
+}
+
+// EducationLevelsItem — элемент массива education.levels
+type EducationLevelsItem struct {
+	From        *int    `json:"from,omitempty"`
+	Institution *string `json:"institution,omitempty"`
+	Level       *string `json:"level,omitempty"`
+	Specialty   *string `json:"specialty,omitempty"`
+	To          *int    `json:"to,omitempty"`
+}
+
+// Failures — раздел failures
+type Failures struct {
+	Recovery []interface{} `json:"recovery,omitempty"`
+}
+
+// Family — раздел family
+type Family struct {
+	Childhood       FamilyChildhood `json:"childhood"`
+	EarlyMemories   []interface{}   `json:"early_memories,omitempty"`
+	Parents         FamilyParents   `json:"parents"`
+	Siblings        FamilySiblings  `json:"siblings"`
+	UpbringingStyle *string         `json:"upbringing_style,omitempty"`
+}
+
+// FamilyChildhood — раздел family.childhood
+type FamilyChildhood struct {
+	Atmosphere *string                      `json:"atmosphere,omitempty"`
+	Members    []FamilyChildhoodMembersItem `json:"members,omitempty"`
+	Structure  *string                      `json:"structure,omitempty"`
+}
+
+// FamilyChildhoodMembersItem — элемент массива family.childhood.members
+type FamilyChildhoodMembersItem struct {
+	Name     *string `json:"name,omitempty"`
+	Relation *string `json:"relation,omitempty"`
+	Role     *string `json:"role,omitempty"`
+}
+
+// FamilyParents — раздел family.parents
+type FamilyParents struct {
+	Influence    *string `json:"influence,omitempty"`
+	Relationship *string `json:"relationship,omitempty"`
+}
+
+// FamilySiblings — раздел family.siblings
+type FamilySiblings struct {
+	Count    *int    `json:"count,omitempty"`
+	Dynamics *string `json:"dynamics,omitempty"`
+}
+
+// Future — раздел future
+type Future struct {
+	CareerAspirations []interface{} `json:"career_aspirations,omitempty"`
+	DreamScenarios    []interface{} `json:"dream_scenarios,omitempty"`
+	LongTermVision    *string       `json:"long_term_vision,omitempty"`
+	PersonalGoals     []interface{} `json:"personal_goals,omitempty"`
+	ShortTermGoals    []interface{} `json:"short_term_goals,omitempty"`
+}
+
+// Health — раздел health
+type Health struct {
+	DietPreferences   []interface{} `json:"diet_preferences,omitempty"`
+	FitnessRoutine    *string       `json:"fitness_routine,omitempty"`
+	LifestyleHabits   []interface{} `json:"lifestyle_habits,omitempty"`
+	MentalWellbeing   *string       `json:"mental_wellbeing,omitempty"`
+	PhysicalCondition *string       `json:"physical_condition,omitempty"`
+	SleepPatterns     *string       `json:"sleep_patterns,omitempty"`
+	StressManagement  []interface{} `json:"stress_management,omitempty"`
+}
+
+// Hobbies — раздел hobbies
+type Hobbies struct {
+	CreativePursuits []interface{} `json:"creative_pursuits,omitempty"`
+	Current          []interface{} `json:"current,omitempty"`
+	SportsActivities []interface{} `json:"sports_activities,omitempty"`
+}
+
+// Impact — раздел impact
+type Impact struct {
+	OnOthers []interface{} `json:"on_others,omitempty"`
+}
+
+// Intellectual — раздел intellectual
+type Intellectual struct {
+	Achievements []interface{} `json:"achievements,omitempty"`
+	Interests    []interface{} `json:"interests,omitempty"`
+}
+
+// Interests — раздел interests
+type Interests struct {
+	Cultural     []interface{} `json:"cultural,omitempty"`
+	Intellectual []interface{} `json:"intellectual,omitempty"`
+}
+
+// Leisure — раздел leisure
+type Leisure struct {
+	Preferences []interface{} `json:"preferences,omitempty"`
+}
+
+// Location — раздел location
+type Location struct {
+	Current LocationCurrent `json:"current"`
+}
+
+// LocationCurrent — раздел location.current
+type LocationCurrent struct {
+	City    *string `json:"city,omitempty"`
+	Country *string `json:"country,omitempty"`
+}
+
+// Motivation — раздел motivation
+type Motivation struct {
+	DrivingForces []interface{} `json:"driving_forces,omitempty"`
+}
+
+// Obstacles — раздел obstacles
+type Obstacles struct {
+	Overcome []interface{} `json:"overcome,omitempty"`
+}
+
+// PersonalGrowth — раздел personal_growth
+type PersonalGrowth struct {
+	FormativePeriods   []interface{} `json:"formative_periods,omitempty"`
+	KeyInsights        []interface{} `json:"key_insights,omitempty"`
+	PersonalityChanges []interface{} `json:"personality_changes,omitempty"`
+}
+
+// Personality — раздел personality
+type Personality struct {
+	AreasForGrowth []interface{} `json:"areas_for_growth,omitempty"`
+	Strengths      []interface{} `json:"strengths,omitempty"`
+	Traits         []interface{} `json:"traits,omitempty"`
+	// тип личности по MBTI, только если его можно обоснованно определить по ответам
+	Type *string `json:"type,omitempty"`
+}
+
+// Planning — раздел planning
+type Planning struct {
+	Strategies []interface{} `json:"strategies,omitempty"`
+}
+
+// Profession — раздел profession
+type Profession struct {
+	Expertise []interface{} `json:"expertise,omitempty"`
+	Projects  []interface{} `json:"projects,omitempty"`
+}
+
+// Recognition — раздел recognition
+type Recognition struct {
+	Awards []interface{} `json:"awards,omitempty"`
+}
+
+// Relationships — раздел relationships
+type Relationships struct {
+	CommunicationStyle *string       `json:"communication_style,omitempty"`
+	ConflictResolution *string       `json:"conflict_resolution,omitempty"`
+	FamilyCurrent      *string       `json:"family_current,omitempty"`
+	FriendshipApproach []interface{} `json:"friendship_approach,omitempty"`
+	// как человек заводит и поддерживает профессиональные и деловые связи
+	Networking   *string               `json:"networking,omitempty"`
+	Romantic     RelationshipsRomantic `json:"romantic"`
+	SocialCircle *string               `json:"social_circle,omitempty"`
+}
+
+// RelationshipsRomantic — раздел relationships.romantic
+type RelationshipsRomantic struct {
+	Status *string       `json:"status,omitempty"`
+	Values []interface{} `json:"values,omitempty"`
+}
+
+// Resilience — раздел resilience
+type Resilience struct {
+	Examples      []interface{} `json:"examples,omitempty"`
+	GrowthMindset *string       `json:"growth_mindset,omitempty"`
+}
+
+// Social — раздел social
+type Social struct {
+	Networks []interface{} `json:"networks,omitempty"`
+}
+
+// Success — раздел success
+type Success struct {
+	DefiningMoments []interface{} `json:"defining_moments,omitempty"`
+}
+
+// Values — раздел values
+type Values struct {
+	CoreBeliefs    []interface{} `json:"core_beliefs,omitempty"`
+	LifePrinciples []interface{} `json:"life_principles,omitempty"`
+	MoralCompass   []interface{} `json:"moral_compass,omitempty"`
+	PoliticalViews *string       `json:"political_views,omitempty"`
+	SocialCauses   []interface{} `json:"social_causes,omitempty"`
+	SpiritualViews *string       `json:"spiritual_views,omitempty"`
+}
+
+// Wellness — раздел wellness
+type Wellness struct {
+	Practices []interface{} `json:"practices,omitempty"`
+}
+
+// Worldview — раздел worldview
+type Worldview struct {
+	MeaningOfLife *string `json:"meaning_of_life,omitempty"`
+	Philosophy    *string `json:"philosophy,omitempty"`
+}
+
+// Decode разбирает профиль в формате profile_<id>.json
+func Decode(data []byte) (*Profile, error) {
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"profile-extractor/pkg/extractor"
+)
+
+// runRender выводит сохраненный профиль в Markdown для чтения человеком
+func runRender(ctx context.Context, args []string) error {
+	flags := newFlagSet("render", "[флаги] <profile.json>")
+	output := flags.String("o", "", "файл Markdown (по умолчанию stdout)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return usageError("render expects one profile file")
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error reading profile: %w", err)
+	}
+	profile, err := extractor.ParseProfile(data)
+	if err != nil {
+		return err
+	}
+
+	markdown := renderMarkdown(profile)
+	if *output == "" {
+		fmt.Print(markdown)
+		return nil
+	}
+	if err := os.WriteFile(*output, []byte(markdown), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", *output, err)
+	}
+	return nil
+}
+
+// renderMarkdown превращает профиль в Markdown: объекты становятся заголовками,
+// значения — пунктами списка. Пустые поля и _metadata пропускаются.
+func renderMarkdown(profile *extractor.Profile) string {
+	var b strings.Builder
+
+	title := "Профиль"
+	if id := profile.InterviewID(); id != "" {
+		title += " " + id
+	}
+	fmt.Fprintf(&b, "# %s\n", title)
+
+	renderObject(&b, profile.Data, 2)
+
+	return b.String()
+}
+
+// renderObject выводит сначала значения объекта, затем вложенные объекты
+func renderObject(b *strings.Builder, object map[string]interface{}, level int) {
+	keys := make([]string, 0, len(object))
+	for key, value := range object {
+		if !isEmptyValue(value) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	listed := false
+	for _, key := range keys {
+		if _, ok := object[key].(map[string]interface{}); ok {
+			continue
+		}
+		if !listed {
+			b.WriteString("\n")
+			listed = true
+		}
+		renderItem(b, key, object[key])
+	}
+
+	for _, key := range keys {
+		nested, ok := object[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// В Markdown не больше шести уровней заголовков
+		heading := level
+		if heading > 6 {
+			heading = 6
+		}
+		fmt.Fprintf(b, "\n%s %s\n", strings.Repeat("#", heading), humanizeKey(key))
+		renderObject(b, nested, level+1)
+	}
+}
+
+// renderItem выводит поле пунктом списка; массив объектов — вложенным списком
+func renderItem(b *strings.Builder, key string, value interface{}) {
+	items, ok := value.([]interface{})
+	if !ok || !hasObjects(items) {
+		fmt.Fprintf(b, "- **%s**: %s\n", humanizeKey(key), inlineValue(value))
+		return
+	}
+
+	fmt.Fprintf(b, "- **%s**:\n", humanizeKey(key))
+	for _, item := range items {
+		if !isEmptyValue(item) {
+			fmt.Fprintf(b, "  - %s\n", inlineValue(item))
+		}
+	}
+}
+
+// inlineValue форматирует значение в одну строку
+func inlineValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "да"
+		}
+		return "нет"
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if !isEmptyValue(item) {
+				parts = append(parts, inlineValue(item))
+			}
+		}
+		return strings.Join(parts, ", ")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key, item := range v {
+			if !isEmptyValue(item) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			parts = append(parts, fmt.Sprintf("%s: %s", humanizeKey(key), inlineValue(v[key])))
+		}
+		return strings.Join(parts, "; ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func hasObjects(items []interface{}) bool {
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue сообщает, что в значении нет данных: null, пустая строка,
+// пустой массив или объект только из пустых полей
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		for _, item := range v {
+			if !isEmptyValue(item) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		for _, item := range v {
+			if !isEmptyValue(item) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// humanizeKey превращает ключ вида early_memories в "Early memories"
+func humanizeKey(key string) string {
+	text := strings.ReplaceAll(key, "_", " ")
+	r, size := utf8.DecodeRuneInString(text)
+	if r == utf8.RuneError {
+		return text
+	}
+	return string(unicode.ToUpper(r)) + text[size:]
+}
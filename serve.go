@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"profile-extractor/internal/api"
+	"profile-extractor/internal/interview"
+	"profile-extractor/pkg/extractor"
+)
+
+// maxInterviewBytes ограничивает размер тела запроса с интервью
+const maxInterviewBytes = 10 << 20
+
+// runServe запускает HTTP сервер: POST /extract принимает JSON интервью
+// и возвращает профиль с _metadata, GET /healthz — проверка живости
+func runServe(ctx context.Context, args []string) error {
+	flags := newFlagSet("serve", "[флаги]")
+	opts := commonFlags(flags)
+	opts.modelFlags(flags)
+	addr := flags.String("addr", "127.0.0.1:8080", "адрес сервера")
+	save := flags.Bool("save", false, "сохранять профили в output_dir")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return usageError("serve takes no arguments")
+	}
+
+	// Запросы обрабатываются параллельно, прогресс потока в консоли перемешался бы
+	e, err := opts.extractor(false)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.Handle("/extract", &extractHandler{extractor: e, save: *save})
+
+	server := &http.Server{
+		Addr:    *addr,
+		Handler: mux,
+		// Остановка сервера отменяет запросы к модели
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Listening on %s", *addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving: %w", err)
+	}
+	return nil
+}
+
+// extractHandler извлекает профиль из интервью в теле запроса
+type extractHandler struct {
+	extractor *extractor.Extractor
+	save      bool
+}
+
+func (h *extractHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("use POST with interview JSON"))
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxInterviewBytes))
+	if err != nil {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	interviewObj, err := interview.ParseInterviewJSON(data)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	profile, _, err := h.extractor.Extract(r.Context(), interviewObj)
+	if err == nil {
+		data, err = profile.JSON()
+	}
+	if err != nil {
+		log.Printf("Interview %s failed: %v", interviewObj.InterviewID, err)
+		writeJSONError(w, errorStatus(err), err)
+		return
+	}
+
+	if h.save {
+		// Профиль уже оплачен, поэтому ошибка записи не отменяет ответ
+		path := outputPath(h.extractor.Config().OutputDir, interviewObj.InterviewID)
+		if err := saveProfile(path, data); err != nil {
+			log.Printf("Saving profile failed: %v", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// errorStatus выбирает HTTP статус по ошибке обработки
+func errorStatus(err error) int {
+	var apiErr *api.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.As(err, &apiErr):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}